@@ -0,0 +1,96 @@
+package go_default
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// AllocPolicy decide when a nil pointer to a struct is allocated to dive into it
+type AllocPolicy int
+
+const (
+	// AllocAlways allocate every nil pointer, the default
+	AllocAlways AllocPolicy = iota
+	// AllocLazy allocate a nil pointer only if at least one nested default value is applied
+	AllocLazy
+	// AllocNever never allocate, only dive into the pointers already set, so nil keeps meaning disabled
+	AllocNever
+)
+
+var allocPolicies = map[string]AllocPolicy{
+	"always": AllocAlways,
+	"lazy":   AllocLazy,
+	"never":  AllocNever,
+}
+
+// WithAllocPolicy set how nil pointers to structs are allocated when diving, a field can override it by the alloc
+// option, like `default:"dive,alloc=lazy"`
+func WithAllocPolicy(policy AllocPolicy) Option {
+	return func(cfg *Config) {
+		cfg.alloc = policy
+	}
+}
+
+// allocPolicy return the policy of the alloc option of the tag, or the one of the config
+func (cfg *Config) allocPolicy(path string, tag Tag) (AllocPolicy, error) {
+	name, ok := tag.Option("alloc")
+	if !ok {
+		return cfg.alloc, nil
+	}
+	policy, ok := allocPolicies[name]
+	if !ok {
+		return 0, fmt.Errorf("cannot set default value for %s, unknown alloc %s, valid values: always, lazy, never", path, name)
+	}
+	return policy, nil
+}
+
+// diveLazy dive into a new struct for a nil pointer, and only keep it if a nested value was set
+//
+// A discarded struct is not validated, as a nil pointer means the section is disabled.
+func diveLazy(path string, fieldValue reflect.Value, cfg *Config) error {
+	applied := cfg.applied
+	value := reflect.New(fieldValue.Type()).Elem()
+	if err := diveStruct(path, value, Tag{Options: map[string]string{"alloc": "always"}}, cfg); err != nil {
+		return err
+	}
+	if cfg.applied > applied || !isZeroStruct(value) {
+		fieldValue.Set(value)
+		return nil
+	}
+	cfg.applied = applied
+	cfg.forget(path)
+	return nil
+}
+
+// isZeroStruct report whether the struct behind the pointers of v is zero
+func isZeroStruct(v reflect.Value) bool {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	return v.IsZero()
+}
+
+// forget remove the provenance and the queued validations of the subtree of a discarded struct
+func (cfg *Config) forget(path string) {
+	for p := range cfg.provenance {
+		if isSubpath(p, path) {
+			delete(cfg.provenance, p)
+		}
+	}
+	validators := cfg.validators[:0]
+	for _, v := range cfg.validators {
+		if v.path != path && !isSubpath(v.path, path) {
+			validators = append(validators, v)
+		}
+	}
+	cfg.validators = validators
+}
+
+// isSubpath report whether p is a path under parent, like "Server.Port" or "Servers[0]" under "Server" or "Servers"
+func isSubpath(p, parent string) bool {
+	return len(p) > len(parent) && strings.HasPrefix(p, parent) && (p[len(parent)] == '.' || p[len(parent)] == '[')
+}
@@ -0,0 +1,92 @@
+package go_default
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type AllocFeature struct {
+	Name  string `default:"feature"`
+	Limit int
+}
+
+type AllocEmpty struct {
+	Name  string
+	Limit int
+}
+
+type AllocStrict struct {
+	Name string
+}
+
+func (s AllocStrict) Validate() error {
+	if s.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+type AllocConfig struct {
+	Feature  *AllocFeature  `default:"dive"`
+	Empty    *AllocEmpty    `default:"dive"`
+	Lazy     *AllocEmpty    `default:"dive,alloc=lazy"`
+	Never    *AllocFeature  `default:"dive,alloc=never"`
+	Always   *AllocEmpty    `default:"dive,alloc=always"`
+	Deep     **AllocFeature `default:"dive"`
+	Strict   *AllocStrict   `default:"dive"`
+	Features []*AllocEmpty  `default:"dive"`
+}
+
+func TestStruct_AllocPolicy(t *testing.T) {
+	t.Run("always", func(t *testing.T) {
+		var cfg AllocConfig
+		err := Struct(&cfg)
+		require.EqualError(t, err, "validate Strict failed: name is required")
+		require.EqualValues(t, "feature", cfg.Feature.Name)
+		require.NotNil(t, cfg.Empty)
+		require.Nil(t, cfg.Lazy)
+		require.Nil(t, cfg.Never)
+		require.NotNil(t, cfg.Always)
+	})
+	t.Run("lazy", func(t *testing.T) {
+		cfg := AllocConfig{Features: []*AllocEmpty{nil, {Limit: 1}}}
+		p := Provenance{}
+		require.NoError(t, Struct(&cfg, WithAllocPolicy(AllocLazy), WithProvenance(p)))
+		require.EqualValues(t, "feature", cfg.Feature.Name)
+		require.EqualValues(t, "feature", (**cfg.Deep).Name)
+		require.Nil(t, cfg.Empty)
+		require.Nil(t, cfg.Lazy)
+		require.Nil(t, cfg.Never)
+		require.NotNil(t, cfg.Always)
+		require.Nil(t, cfg.Strict)
+		require.EqualValues(t, []*AllocEmpty{nil, {Limit: 1}}, cfg.Features)
+		require.EqualValues(t, "tag", p["Feature.Name"])
+	})
+	t.Run("lazy by source", func(t *testing.T) {
+		var cfg AllocConfig
+		require.NoError(t, Struct(&cfg, WithAllocPolicy(AllocLazy), WithSources(MapSource{
+			"Empty.Limit": "5",
+			"Strict.Name": "strict",
+		})))
+		require.EqualValues(t, AllocEmpty{Limit: 5}, *cfg.Empty)
+		require.EqualValues(t, AllocStrict{Name: "strict"}, *cfg.Strict)
+		require.Nil(t, cfg.Lazy)
+	})
+	t.Run("never", func(t *testing.T) {
+		cfg := AllocConfig{Feature: &AllocFeature{Limit: 1}}
+		require.NoError(t, Struct(&cfg, WithAllocPolicy(AllocNever)))
+		require.EqualValues(t, AllocFeature{Name: "feature", Limit: 1}, *cfg.Feature)
+		require.Nil(t, cfg.Empty)
+		require.Nil(t, cfg.Lazy)
+		require.Nil(t, cfg.Deep)
+		require.NotNil(t, cfg.Always)
+	})
+	t.Run("unknown alloc", func(t *testing.T) {
+		var cfg struct {
+			Feature *AllocFeature `default:"dive,alloc=sometimes"`
+		}
+		require.EqualError(t, Struct(&cfg), "cannot set default value for Feature, unknown alloc sometimes, valid values: always, lazy, never")
+	})
+}
@@ -0,0 +1,89 @@
+package go_default
+
+import (
+	"reflect"
+)
+
+// WithAutoDive dive into every untagged struct, pointer to struct and embedded struct that has default values
+//
+// A struct has default values if one of its fields has a tag, a registered default or a Default method, if it
+// implements a lifecycle hook, or if one of its untagged structs has default values. Other structs, like time.Time
+// or url.URL, are left to the setters. Use `default:"-"` to opt out, nil pointers follow the allocation policy.
+func WithAutoDive() Option {
+	return func(cfg *Config) {
+		cfg.autoDive = true
+	}
+}
+
+// isAutoDive report whether an untagged field is dived into by WithAutoDive
+//
+// A nil pointer to a struct being filled is not dived into, so recursive types like linked lists terminate.
+func (cfg *Config) isAutoDive(field reflect.StructField, fieldValue reflect.Value, tagValue string) bool {
+	if !cfg.autoDive || tagValue != "" || !field.IsExported() && !isEmbeddedStruct(field) || !isStruct(field.Type) {
+		return false
+	}
+	if fieldValue.Kind() == reflect.Pointer && fieldValue.IsNil() && cfg.isDiving(field.Type) {
+		return false
+	}
+	return hasDefaults(field.Type, cfg, map[reflect.Type]bool{})
+}
+
+// isDiving report whether the struct behind the pointers of t is being filled
+func (cfg *Config) isDiving(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	for _, diving := range cfg.diving {
+		if diving == t {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDefaults report whether the struct behind the pointers of t has default values to apply
+func hasDefaults(t reflect.Type, cfg *Config, visited map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+
+	ptr := reflect.PointerTo(t)
+	for _, hook := range []reflect.Type{
+		reflect.TypeOf((*BeforeDefaulter)(nil)).Elem(),
+		reflect.TypeOf((*Defaulter)(nil)).Elem(),
+		reflect.TypeOf((*Validator)(nil)).Elem(),
+	} {
+		if ptr.Implements(hook) {
+			return true
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !isEmbeddedStruct(field) {
+			continue
+		}
+		tagValue := tagValueOf(t, field, cfg)
+		if tagValue != "" && tagValue != "-" {
+			return true
+		}
+		if tagValue == "" {
+			if _, ok := ptr.MethodByName("Default" + field.Name); ok {
+				return true
+			}
+			if isStruct(field.Type) && hasDefaults(field.Type, cfg, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isEmbeddedStruct report whether a field is an embedded struct, whose exported fields are promoted even if its type
+// is unexported, like by encoding/json. A pointer to an unexported struct can't be allocated, so it's not.
+func isEmbeddedStruct(field reflect.StructField) bool {
+	return field.Anonymous && field.Type.Kind() == reflect.Struct
+}
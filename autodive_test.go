@@ -0,0 +1,123 @@
+package go_default
+
+import (
+	"flag"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type AutoBase struct {
+	Region string `default:"us"`
+}
+
+type AutoServer struct {
+	Host string `default:"localhost"`
+	TLS  AutoTLS
+}
+
+type AutoTLS struct {
+	MinVersion string `default:"1.2"`
+}
+
+type AutoHooked struct {
+	Addr string
+}
+
+func (h *AutoHooked) SetDefaults() {
+	h.Addr = "hooked"
+}
+
+type AutoPlain struct {
+	Name string
+}
+
+type AutoConfig struct {
+	AutoBase
+	*AutoTLS
+	Server  AutoServer
+	Backup  *AutoServer
+	Hooked  AutoHooked
+	Plain   *AutoPlain
+	Skipped AutoServer `default:"-"`
+	Started time.Time
+	URL     *url.URL
+	Tagged  *AutoServer `default:"dive,alloc=never"`
+	Self    *AutoConfig
+}
+
+type autoBase struct {
+	Region string `default:"us"`
+}
+
+type AutoEmbedded struct {
+	autoBase
+	*AutoTLS
+	Name string `default:"app"`
+}
+
+func TestStruct_AutoDive(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		var cfg AutoConfig
+		require.NoError(t, Struct(&cfg))
+		require.Empty(t, cfg.Region)
+		require.Nil(t, cfg.AutoTLS)
+		require.Empty(t, cfg.Server.Host)
+	})
+	t.Run("enabled", func(t *testing.T) {
+		var cfg AutoConfig
+		require.NoError(t, Struct(&cfg, WithAutoDive()))
+		require.EqualValues(t, "us", cfg.Region)
+		require.EqualValues(t, "1.2", cfg.AutoTLS.MinVersion)
+		require.EqualValues(t, AutoServer{Host: "localhost", TLS: AutoTLS{MinVersion: "1.2"}}, cfg.Server)
+		require.EqualValues(t, "localhost", cfg.Backup.Host)
+		require.EqualValues(t, "hooked", cfg.Hooked.Addr)
+		require.Nil(t, cfg.Plain)
+		require.Empty(t, cfg.Skipped.Host)
+		require.True(t, cfg.Started.IsZero())
+		require.Nil(t, cfg.URL)
+		require.Nil(t, cfg.Tagged)
+		require.Nil(t, cfg.Self)
+	})
+	t.Run("alloc policy", func(t *testing.T) {
+		cfg := AutoConfig{Server: AutoServer{Host: "set"}}
+		require.NoError(t, Struct(&cfg, WithAutoDive(), WithAllocPolicy(AllocNever)))
+		require.EqualValues(t, "1.2", cfg.Server.TLS.MinVersion)
+		require.Nil(t, cfg.AutoTLS)
+		require.Nil(t, cfg.Backup)
+	})
+	t.Run("unexported embedded", func(t *testing.T) {
+		var cfg AutoEmbedded
+		require.NoError(t, Struct(&cfg))
+		require.Empty(t, cfg.Region)
+
+		p := Provenance{}
+		require.NoError(t, Struct(&cfg, WithAutoDive(), WithProvenance(p)))
+		require.EqualValues(t, "us", cfg.Region)
+		require.EqualValues(t, "1.2", cfg.MinVersion)
+		require.EqualValues(t, "app", cfg.Name)
+		require.EqualValues(t, SourceTag, p["autoBase.Region"])
+
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		require.NoError(t, RegisterFlags(fs, &cfg, WithAutoDive()))
+		require.NoError(t, fs.Parse([]string{"-autobase.region=eu"}))
+		require.EqualValues(t, "eu", cfg.Region)
+	})
+	t.Run("flags", func(t *testing.T) {
+		var cfg AutoConfig
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		require.NoError(t, RegisterFlags(fs, &cfg, WithAutoDive()))
+		require.NoError(t, fs.Parse([]string{"-server.tls.minversion=1.3", "-autobase.region=eu"}))
+		require.EqualValues(t, "1.3", cfg.Server.TLS.MinVersion)
+		require.EqualValues(t, "eu", cfg.Region)
+		require.Nil(t, cfg.Tagged)
+		require.Nil(t, cfg.Self)
+		require.EqualValues(t, "localhost", fs.Lookup("tagged.host").DefValue)
+
+		// the first flag set under a nil pointer allocates it, with its defaults
+		require.NoError(t, fs.Parse([]string{"-tagged.tls.minversion=1.1"}))
+		require.EqualValues(t, AutoServer{Host: "localhost", TLS: AutoTLS{MinVersion: "1.1"}}, *cfg.Tagged)
+	})
+}
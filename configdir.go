@@ -0,0 +1,56 @@
+package go_default
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WithConfigDir override the tag values by the files of a directory, one file per variable, like a mounted config volume
+//
+// The file names are derived from the field paths like the environment variables of WithEnv, so "Server.Port" is read
+// from "SERVER_PORT" by default, and opts can change the naming. Trailing newlines are trimmed from the contents.
+// The directory is read whenever Struct applies the option, so the values follow the updates of a mounted volume.
+func WithConfigDir(dir string, opts ...EnvOption) Option {
+	return func(cfg *Config) {
+		values, err := ReadConfigDir(dir)
+		if err != nil {
+			cfg.errs = append(cfg.errs, err)
+			return
+		}
+		src := NewEnvSource(append([]EnvOption{WithEnvLookup(MapLookupEnv(values))}, opts...)...)
+		src.Name = "dir " + dir
+		WithSources(src)(cfg)
+	}
+}
+
+// ReadConfigDir read the regular files of a directory keyed by file name, with trailing newlines trimmed
+//
+// Hidden files and directories are ignored, like the "..data" entries of Kubernetes volumes, and symlinks are followed.
+func ReadConfigDir(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read config dir %s failed: %w", dir, err)
+	}
+	values := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		name := filepath.Join(dir, entry.Name())
+		info, err := os.Stat(name)
+		if err != nil {
+			return nil, fmt.Errorf("read config file %s failed: %w", name, err)
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		b, err := os.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("read config file %s failed: %w", name, err)
+		}
+		values[entry.Name()] = strings.TrimRight(string(b), "\r\n")
+	}
+	return values, nil
+}
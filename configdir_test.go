@@ -0,0 +1,67 @@
+package go_default
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStruct_ConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "SERVER_PORT"), []byte("9000\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "SERVER_TIMEOUT"), []byte("5s\r\n\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "SERVICE_TOKEN"), []byte("line1\nline2\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".hidden"), []byte("x"), 0600))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "..data"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "..data", "NAME"), []byte("data"), 0600))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "..data", "NAME"), filepath.Join(dir, "NAME")))
+
+	t.Run("set", func(t *testing.T) {
+		var cfg EnvConfig
+		err := Struct(&cfg, WithConfigDir(dir))
+		require.NoError(t, err)
+		require.EqualValues(t, "data", cfg.Name)
+		require.EqualValues(t, 9000, cfg.Server.Port)
+		require.EqualValues(t, 5*time.Second, cfg.Server.Timeout)
+		require.EqualValues(t, "line1\nline2", cfg.Server.Token)
+		require.EqualValues(t, "localhost", cfg.Server.Host)
+	})
+	t.Run("custom naming", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "server.max-conns"), []byte("42"), 0600))
+		var cfg EnvConfig
+		err := Struct(&cfg, WithConfigDir(dir, WithEnvSeparator("."), WithEnvMangle(func(segment string) string {
+			return map[string]string{"Server": "server", "MaxConns": "max-conns"}[segment]
+		})))
+		require.NoError(t, err)
+		require.EqualValues(t, 42, cfg.Server.MaxConns)
+	})
+	t.Run("should return error with field path when value fails to parse", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "SERVER_PORT"), []byte("http\n"), 0600))
+		var cfg EnvConfig
+		err := Struct(&cfg, WithConfigDir(dir))
+		require.ErrorContains(t, err, "cannot set default value for Server.Port, parse http to int failed")
+	})
+	t.Run("read when applied", func(t *testing.T) {
+		dir := t.TempDir()
+		opt := WithConfigDir(dir)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "SERVER_PORT"), []byte("9000"), 0600))
+		var cfg EnvConfig
+		require.NoError(t, Struct(&cfg, opt))
+		require.EqualValues(t, 9000, cfg.Server.Port)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "SERVER_PORT"), []byte("9100"), 0600))
+		cfg = EnvConfig{}
+		require.NoError(t, Struct(&cfg, opt))
+		require.EqualValues(t, 9100, cfg.Server.Port)
+	})
+	t.Run("should return error when dir is missing", func(t *testing.T) {
+		var cfg EnvConfig
+		err := Struct(&cfg, WithConfigDir(filepath.Join(dir, "missing")))
+		require.ErrorContains(t, err, "read config dir")
+	})
+}
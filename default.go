@@ -145,9 +145,54 @@ func TextUnmarshalerSetter(path string, fieldValue reflect.Value, value string)
 	}
 }
 
+// TextUnmarshalerValueSetter set the default value for a struct whose pointer implements encoding.TextUnmarshaler,
+// like big.Int
+//
+// It's not one of the DefaultSetters, such fields are left as is unless it's added by WithSetters.
+func TextUnmarshalerValueSetter(path string, fieldValue reflect.Value, value string) (set bool, err error) {
+	if !isTextUnmarshalerValue(fieldValue.Type()) || !fieldValue.CanAddr() {
+		return false, nil
+	}
+	if !fieldValue.IsZero() {
+		return true, nil // already set
+	}
+	if err := fieldValue.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value)); err != nil {
+		return false, fmt.Errorf("cannot set default value for %s, unmarshal %s failed", path, value)
+	}
+	return true, nil
+}
+
+// isTextUnmarshalerValue report whether t is a struct whose pointer implements encoding.TextUnmarshaler
+func isTextUnmarshalerValue(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && reflect.PointerTo(t).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem())
+}
+
 type Config struct {
-	TagName string          // default tag name
-	Setters []DefaultSetter // default setters to convert string to specific type
+	TagName    string              // default tag name
+	TagSetters []TagSetter         // tag setters to convert a parsed tag to specific type, applied before Setters
+	TagOptions []string            // names of the tag options read by the setters, other options return an error
+	Setters    []DefaultSetter     // default setters to convert string to specific type
+	LookupEnv  LookupEnvFunc       // look up environment variables to expand in default values, nil disables the expansion
+	Resolvers  map[string]Resolver // resolvers of values with a scheme prefix, keyed by scheme, nil disables the resolution
+
+	sources    []Source           // sources that override the tag values, later sources take precedence
+	defaults   []*defaultsOverlay // replace the tag values by path, later overlays take precedence
+	provenance Provenance         // record the source of every value set, nil disables the recording
+	present    map[string]bool    // paths set explicitly, like the keys of a decoded JSON, kept even if zero
+	zeroPolicy ZeroPolicy         // report whether a field is unset, nil uses the built-in zero detection
+	overwrite  bool               // set the default values even over the non-zero values
+	reset      *resetPaths        // fields reset to zero before filling, nil resets none
+	alloc      AllocPolicy        // how nil pointers to structs are allocated when diving
+	applied    int                // number of values set, to tell whether a lazy allocation is needed
+	autoDive   bool               // dive into the untagged structs that have default values
+	diving     []reflect.Type     // structs being filled, from the top level one
+	root       reflect.Type       // type of the top level struct pointer, to check the references
+	refs       []*reference       // fields defaulting to other fields, resolved once the whole struct is filled
+	resolved   []*reference       // references resolved so far, resolved again when a flag sets a field
+	validators []validation       // Validate methods to run once the references are resolved
+	sliceMerge MergeStrategy      // how Merge combines non-empty slices
+	mapMerge   MergeStrategy      // how Merge combines non-empty maps
+	errs       []error            // errors of the options, returned by Struct
 }
 
 type Option func(cfg *Config)
@@ -166,104 +211,374 @@ func WithSetters(setters ...DefaultSetter) Option {
 	}
 }
 
+// WithTagSetters set the tag setters to convert a parsed tag to specific type
+func WithTagSetters(setters ...TagSetter) Option {
+	return func(cfg *Config) {
+		cfg.TagSetters = setters
+	}
+}
+
+// WithTagOptions set the names of the tag options read by the setters, like the options of a custom TagSetter
+func WithTagOptions(options ...string) Option {
+	return func(cfg *Config) {
+		cfg.TagOptions = options
+	}
+}
+
+func DefaultTagOptions() []string {
+	return []string{"layout", "base", "sep", "alloc"}
+}
+
+func DefaultTagSetters() []TagSetter {
+	return []TagSetter{
+		TimeLayoutSetter,
+	}
+}
+
 func DefaultSetters() []DefaultSetter {
 	return []DefaultSetter{
+		JSONSetter,
+		EnumSetter,
 		DurationSetter,
 		TimeSetter,
 		URLSetter,
 		IPAddrSetter,
 		ByteSliceSetter,
+		FileModeSetter,
+		PathSetter,
 		TextUnmarshalerSetter,
 	}
 }
 
 // Struct set the default value for a struct
 func Struct(input any, opts ...Option) error {
-	cfg := &Config{
-		TagName: "default",
-		Setters: DefaultSetters(),
-	}
+	return newConfig(opts...).fill(input)
+}
 
-	for _, opt := range opts {
-		opt(cfg)
+// fill set the default values of input by cfg
+func (cfg *Config) fill(input any) error {
+	if len(cfg.errs) > 0 {
+		return cfg.errs[0]
 	}
 
 	v := reflect.ValueOf(input)
-	t := v.Type()
-	if t.Kind() != reflect.Pointer {
+	if !isStructPointer(v) {
 		return ErrNotPointer
 	}
-	t = t.Elem()
-	if t.Kind() != reflect.Struct {
-		return ErrNotPointer
+
+	cfg.root = v.Type()
+	if err := fillStruct("", v, Tag{}, cfg); err != nil {
+		return err
+	}
+	if err := cfg.finish(v); err != nil {
+		return err
+	}
+	for _, overlay := range cfg.defaults {
+		if err := overlay.check(); err != nil {
+			return err
+		}
+	}
+	if cfg.reset != nil {
+		return cfg.reset.check()
+	}
+	return nil
+}
+
+// finish resolve the references, then run the Validate methods, once the fields under root are filled
+func (cfg *Config) finish(root reflect.Value) error {
+	if err := cfg.resolveRefs(root); err != nil {
+		return err
 	}
+	return cfg.validate()
+}
 
-	return fillStruct("", v, "", cfg)
+func newConfig(opts ...Option) *Config {
+	cfg := &Config{
+		TagName:    "default",
+		TagSetters: DefaultTagSetters(),
+		TagOptions: DefaultTagOptions(),
+		Setters:    DefaultSetters(),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
 }
 
-func fillStruct(deepName string, value reflect.Value, tagValue string, cfg *Config) error {
+func isStructPointer(v reflect.Value) bool {
+	return v.Kind() == reflect.Pointer && v.Type().Elem().Kind() == reflect.Struct
+}
+
+func fillStruct(deepName string, value reflect.Value, tag Tag, cfg *Config) error {
 	if value.Type().Elem().Kind() == reflect.Struct {
+		// the hooks of an unexported embedded struct can't be called
+		if hook, ok := interfaceOf(value).(BeforeDefaulter); ok {
+			hook.BeforeDefaults()
+		}
 		t := value.Type().Elem()
+		cfg.diving = append(cfg.diving, t)
+		defer func() { cfg.diving = cfg.diving[:len(cfg.diving)-1] }()
 		for i := 0; i < t.NumField(); i++ {
 			field := t.Field(i)
-			tagValue := field.Tag.Get(cfg.TagName)
-			if tagValue == "" {
-				continue
-			}
 			fieldValue := value.Elem().Field(i)
-
 			path := path(deepName, field.Name)
-			if !isDefault(fieldValue) {
+
+			tagValue := tagValueOf(t, field, cfg)
+			if cfg.isAutoDive(field, fieldValue, tagValue) {
+				tagValue = "dive"
+			}
+			tag, err := ParseTag(tagValue)
+			if err != nil {
+				return fmt.Errorf("cannot set default value for %s, parse tag %s failed: %w", path, tagValue, err)
+			}
+			if err := cfg.checkOptions(tag); err != nil {
+				return fmt.Errorf("cannot set default value for %s, %w", path, err)
+			}
+			if err := cfg.checkRefs(tag); err != nil {
+				return fmt.Errorf("cannot set default value for %s, %w", path, err)
+			}
+			if tag.IsSkip() {
+				skipDefaults(path, cfg)
 				continue
 			}
+			if cfg.reset != nil && cfg.reset.match(path) && !tag.IsDive() && fieldValue.CanSet() {
+				// a dive field is not reset as a whole, so the skipped fields of its subtree are kept
+				fieldValue.Set(reflect.Zero(fieldValue.Type()))
+			}
+			source := SourceTag
+			if field.IsExported() && !tag.IsDive() {
+				if value, name, ok := lookupDefault(path, cfg); ok {
+					tag = Tag{Value: value, Quoted: true, Options: tag.Options}
+					source = name
+				}
+				_, hasMethod := defaultMethodName(value, field, tag, tagValue)
+				if value, name, ok := lookupOverride(path, field, tagValue != "" || hasMethod, cfg); ok {
+					// an override replaces the current value and is converted like a tag value
+					if value, err = resolve(path, value, cfg); err != nil {
+						return err
+					}
+					if !acceptsValue(path, fieldValue, value, cfg) {
+						continue // a nested struct without dive, only filled by the setters
+					}
+					fieldValue.Set(reflect.Zero(fieldValue.Type()))
+					if err := fillSome(path, fieldValue, Tag{Value: value, Quoted: true, Options: tag.Options}, cfg); err != nil {
+						return err
+					}
+					cfg.record(path, name)
+					continue
+				}
+			}
 
-			if err := fillSome(path, fieldValue, tagValue, cfg); err != nil {
+			fromTag := source == SourceTag
+			if !tag.IsDive() && cfg.present[path] {
+				continue // set explicitly, even to a zero value
+			}
+			if fromTag && field.IsExported() {
+				if name, ok := defaultMethodName(value, field, tag, tagValue); ok {
+					if err := callDefaultMethod(path, value, name, fieldValue, tag, cfg); err != nil {
+						return err
+					}
+					continue
+				}
+			}
+			if fromTag && tagValue == "" {
+				continue
+			}
+			if !tag.IsDive() {
+				unset, reset := cfg.unset(path, fieldValue)
+				if !unset {
+					continue
+				}
+				if reset {
+					fieldValue.Set(reflect.Zero(fieldValue.Type()))
+				}
+			}
+			if refs, ok := parseRefs(tag); fromTag && ok {
+				// resolved once the whole struct is filled, counted as applied for the lazy allocation
+				cfg.refs = append(cfg.refs, &reference{path: path, tag: tag, refs: refs})
+				cfg.applied++
+				continue
+			}
+			if fromTag && cfg.LookupEnv != nil {
+				if tag.Value, err = ExpandEnv(tag.Value, cfg.LookupEnv); err != nil {
+					return fmt.Errorf("cannot set default value for %s, expand %s failed: %w", path, tagValue, err)
+				}
+			}
+			if tag.Value, err = resolve(path, tag.Value, cfg); err != nil {
 				return err
 			}
+			before := snapshot(fieldValue)
+			if err := fillSome(path, fieldValue, tag, cfg); err != nil {
+				return err
+			}
+			if !tag.IsDive() && isWritten(before, fieldValue) {
+				cfg.record(path, source)
+			}
 		}
+		afterDefaults(deepName, value, cfg)
+		return nil
 	} else {
 		// not a pointer to a struct, fill the value by setters or set directly
-		// e.g. *int, *string
+		// e.g. *int, *string, **int
 		value = value.Elem()
 		if !isDefault(value) {
 			return nil
 		}
-		set, err := applySetters(deepName, value, tagValue, cfg.Setters)
-		if err != nil {
-			return err
-		}
-		if set {
-			return nil
-		}
-		return setDefault(deepName, value, tagValue)
+		return fillSome(deepName, value, tag, cfg)
 	}
-	return nil
 }
 
-func fillSome(path string, fieldValue reflect.Value, tagValue string, cfg *Config) error {
-	set, err := applySetters(path, fieldValue, tagValue, cfg.Setters)
+func fillSome(path string, fieldValue reflect.Value, tag Tag, cfg *Config) error {
+	if fieldValue.CanAddr() {
+		if opt, ok := interfaceOf(fieldValue.Addr()).(optionalValue); ok {
+			return fillOptional(path, opt, tag, cfg)
+		}
+	}
+	if tag.IsDive() && isStruct(fieldValue.Type()) {
+		return diveStruct(path, fieldValue, tag, cfg)
+	}
+	if tag.IsDive() && isElemStruct(fieldValue.Type()) {
+		return diveElems(path, fieldValue, tag, cfg)
+	}
+
+	set, err := applySetters(path, fieldValue, tag, cfg)
 	if err != nil {
 		return err
 	}
 	if set {
+		if strings.HasPrefix(tag.Value, JSONPrefix) {
+			// the JSON value is decoded first, then dive to fill what is still zero
+			return diveStruct(path, fieldValue, tag, cfg)
+		}
 		return nil
 	}
 
+	if sep, ok := tag.Option("sep"); ok && (fieldValue.Type().Kind() == reflect.Slice || fieldValue.Type().Kind() == reflect.Array) {
+		return fillElems(path, fieldValue, tag, sep, cfg)
+	}
+
+	if isTextUnmarshalerValue(fieldValue.Type()) {
+		return nil // left as is, unless TextUnmarshalerValueSetter is added
+	}
+	if t := fieldValue.Type(); t.Kind() == reflect.Struct || t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct {
+		// only dive and the values handled by setters are allowed, so typos like "diev" are not hidden
+		return fmt.Errorf("cannot set default value for %s, unknown value %s for %s, use dive to fill the nested struct", path, tag.Value, fieldValue.Type().String())
+	}
+
 	if fieldValue.Type().Kind() == reflect.Pointer {
 		if fieldValue.IsNil() {
 			fieldValue.Set(reflect.New(fieldValue.Type().Elem())) // create a new instance
 		}
-		if err := fillStruct(path, fieldValue, tagValue, cfg); err != nil {
+		if err := fillStruct(path, fieldValue, tag, cfg); err != nil {
 			return err
 		}
-	} else if fieldValue.Type().Kind() == reflect.Struct {
-		if err := fillStruct(path, fieldValue.Addr(), tagValue, cfg); err != nil {
+	} else {
+		if err := setDefault(path, fieldValue, tag); err != nil {
 			return err
 		}
-	} else {
-		if err := setDefault(path, fieldValue, tagValue); err != nil {
+	}
+	return nil
+}
+
+// snapshot return a copy of a field value, or its zero value if it's obtained through an unexported field
+func snapshot(fieldValue reflect.Value) reflect.Value {
+	before := reflect.New(fieldValue.Type()).Elem()
+	if fieldValue.CanInterface() {
+		before.Set(fieldValue)
+	}
+	return before
+}
+
+// isWritten report whether a field holding before was written, rather than kept by a setter as already set,
+// like a non-nil *url.URL
+func isWritten(before, fieldValue reflect.Value) bool {
+	if before.IsZero() || !fieldValue.CanInterface() {
+		return true
+	}
+	return !reflect.DeepEqual(before.Interface(), fieldValue.Interface())
+}
+
+// fillElems split the value by sep and fill each element of a slice or an array, like "a,b,c,sep=','"
+func fillElems(path string, fieldValue reflect.Value, tag Tag, sep string, cfg *Config) error {
+	var parts []string
+	if tag.Value != "" {
+		parts = strings.Split(tag.Value, sep)
+	}
+	if fieldValue.Type().Kind() == reflect.Slice {
+		fieldValue.Set(reflect.MakeSlice(fieldValue.Type(), len(parts), len(parts)))
+	} else if len(parts) > fieldValue.Len() {
+		return fmt.Errorf("cannot set default value for %s, %d elements exceed %s", path, len(parts), fieldValue.Type().String())
+	}
+	for i, part := range parts {
+		elemPath := fmt.Sprintf("%s[%d]", path, i)
+		if err := fillSome(elemPath, fieldValue.Index(i), tag.withValue(part, "sep"), cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isStruct report whether t is a struct or a pointer to a struct at any depth
+func isStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// diveStruct fill the nested struct behind any number of pointers, allocating the nil ones by the allocation policy
+func diveStruct(path string, fieldValue reflect.Value, tag Tag, cfg *Config) error {
+	policy, err := cfg.allocPolicy(path, tag)
+	if err != nil {
+		return err
+	}
+	for fieldValue.Type().Kind() == reflect.Pointer {
+		if fieldValue.IsNil() {
+			switch policy {
+			case AllocNever:
+				return nil
+			case AllocLazy:
+				return diveLazy(path, fieldValue, cfg)
+			}
+			fieldValue.Set(reflect.New(fieldValue.Type().Elem())) // create a new instance
+		}
+		fieldValue = fieldValue.Elem()
+	}
+	if fieldValue.Type().Kind() != reflect.Struct {
+		return nil
+	}
+	return fillStruct(path, fieldValue.Addr(), Tag{}, cfg)
+}
+
+// isElemStruct report whether t is a slice, an array or a map of structs, or of pointers to structs
+func isElemStruct(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return isStruct(t.Elem())
+	default:
+		return false
+	}
+}
+
+// diveElems fill the nested struct of every element of a slice, an array or a map, like "Servers[0]"
+func diveElems(path string, fieldValue reflect.Value, tag Tag, cfg *Config) error {
+	if fieldValue.Type().Kind() != reflect.Map {
+		for i := 0; i < fieldValue.Len(); i++ {
+			if err := diveStruct(fmt.Sprintf("%s[%d]", path, i), fieldValue.Index(i), tag, cfg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	iter := fieldValue.MapRange()
+	for iter.Next() {
+		// map elements are not addressable, so fill a copy and put it back
+		elem := reflect.New(fieldValue.Type().Elem()).Elem()
+		elem.Set(iter.Value())
+		if err := diveStruct(fmt.Sprintf("%s[%v]", path, iter.Key()), elem, tag, cfg); err != nil {
 			return err
 		}
+		fieldValue.SetMapIndex(iter.Key(), elem)
 	}
 	return nil
 }
@@ -274,6 +589,7 @@ func isDefault(fieldValue reflect.Value) bool {
 		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
 		reflect.Float32, reflect.Float64,
+		reflect.Complex64, reflect.Complex128,
 		reflect.Bool:
 		return fieldValue.IsZero()
 	case reflect.Struct, reflect.Pointer:
@@ -285,18 +601,26 @@ func isDefault(fieldValue reflect.Value) bool {
 	}
 }
 
-func setDefault(path string, fieldValue reflect.Value, value string) error {
+func setDefault(path string, fieldValue reflect.Value, tag Tag) error {
+	value := tag.Value
+	base := 10
+	if b, ok := tag.Option("base"); ok {
+		var err error
+		if base, err = strconv.Atoi(b); err != nil {
+			return fmt.Errorf("cannot set default value for %s, invalid base %s", path, b)
+		}
+	}
 	switch fieldValue.Type().Kind() {
 	case reflect.String:
 		fieldValue.SetString(value)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		i, err := strconv.ParseInt(value, 10, 64)
+		i, err := strconv.ParseInt(value, base, 64)
 		if err != nil {
 			return fmt.Errorf("cannot set default value for %s, parse %s to %s failed", path, value, fieldValue.Type().String())
 		}
 		fieldValue.SetInt(i)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		i, err := strconv.ParseUint(value, 10, 64)
+		i, err := strconv.ParseUint(value, base, 64)
 		if err != nil {
 			return fmt.Errorf("cannot set default value for %s, parse %s to %s failed", path, value, fieldValue.Type().String())
 		}
@@ -307,6 +631,12 @@ func setDefault(path string, fieldValue reflect.Value, value string) error {
 			return fmt.Errorf("cannot set default value for %s, parse %s to %s failed", path, value, fieldValue.Type().String())
 		}
 		fieldValue.SetFloat(f)
+	case reflect.Complex64, reflect.Complex128:
+		c, err := strconv.ParseComplex(value, 128)
+		if err != nil {
+			return fmt.Errorf("cannot set default value for %s, parse %s to %s failed", path, value, fieldValue.Type().String())
+		}
+		fieldValue.SetComplex(c)
 	case reflect.Bool:
 		b, err := strconv.ParseBool(value)
 		if err != nil {
@@ -319,9 +649,67 @@ func setDefault(path string, fieldValue reflect.Value, value string) error {
 	return nil
 }
 
-func applySetters(path string, fieldValue reflect.Value, value string, setters []DefaultSetter) (set bool, err error) {
-	for _, setter := range setters {
-		set, err = setter(path, fieldValue, value)
+func lookupDefault(path string, cfg *Config) (value string, source string, found bool) {
+	// look up every overlay so they all see the path, the later ones win like the sources
+	for _, overlay := range cfg.defaults {
+		if v, ok := overlay.lookup(path); ok {
+			value, source, found = v, overlay.String(), true
+		}
+	}
+	return value, source, found
+}
+
+func skipDefaults(path string, cfg *Config) {
+	for _, overlay := range cfg.defaults {
+		overlay.skip(path)
+	}
+	if cfg.reset != nil {
+		cfg.reset.skip(path)
+	}
+}
+
+func lookupOverride(path string, field reflect.StructField, hasDefault bool, cfg *Config) (value string, source string, found bool) {
+	for i := len(cfg.sources) - 1; i >= 0; i-- {
+		src := cfg.sources[i]
+		if defaulted, ok := src.(defaultedSource); ok {
+			value, found = defaulted.lookupDefaulted(path, field, hasDefault)
+		} else if fieldSource, ok := src.(FieldSource); ok {
+			value, found = fieldSource.LookupField(path, field)
+		} else {
+			value, found = src.Lookup(path)
+		}
+		if found {
+			return value, SourceName(src), true
+		}
+	}
+	return "", "", false
+}
+
+// acceptsValue report whether a raw value can be set to a field, a struct is only accepted by an Optional or a setter,
+// so an unrelated value doesn't fail a nested struct that is not dived into
+func acceptsValue(path string, fieldValue reflect.Value, value string, cfg *Config) bool {
+	if !isStruct(fieldValue.Type()) {
+		return true
+	}
+	if _, ok := reflect.New(fieldValue.Type()).Interface().(optionalValue); ok {
+		return true
+	}
+	set, err := applySetters(path, reflect.New(fieldValue.Type()).Elem(), Tag{Value: value, Quoted: true}, cfg)
+	return set || err != nil
+}
+
+func applySetters(path string, fieldValue reflect.Value, tag Tag, cfg *Config) (set bool, err error) {
+	for _, setter := range cfg.TagSetters {
+		set, err = setter(path, fieldValue, tag)
+		if err != nil {
+			return set, err
+		}
+		if set {
+			return set, err
+		}
+	}
+	for _, setter := range cfg.Setters {
+		set, err = setter(path, fieldValue, tag.Value)
 		if err != nil {
 			return set, err
 		}
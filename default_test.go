@@ -247,6 +247,78 @@ func TestStruct_Float64Ptr(t *testing.T) {
 	})
 }
 
+func TestStruct_Complex(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		var foo struct {
+			Complex64  complex64   `default:"1+2i"`
+			Complex128 complex128  `default:"(3.5-4i)"`
+			ValuePtr   *complex128 `default:"2i"`
+		}
+		err := Struct(&foo)
+		require.NoError(t, err)
+		require.EqualValues(t, complex64(1+2i), foo.Complex64)
+		require.EqualValues(t, 3.5-4i, foo.Complex128)
+		require.EqualValues(t, 2i, *foo.ValuePtr)
+	})
+	t.Run("not set", func(t *testing.T) {
+		var foo struct {
+			Complex128 complex128 `default:"1+2i"`
+		}
+		foo.Complex128 = 5i
+		err := Struct(&foo)
+		require.NoError(t, err)
+		require.EqualValues(t, 5i, foo.Complex128)
+	})
+	t.Run("should return error when failed to parse complex", func(t *testing.T) {
+		var foo struct {
+			Complex complex64 `default:"1+2j"`
+		}
+		err := Struct(&foo)
+		require.ErrorContains(t, err, "cannot set default value for Complex, parse 1+2j to complex64 failed")
+	})
+}
+
+func TestStruct_MultiLevelPtr(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		var foo struct {
+			IntPtrPtr     **int           `default:"1"`
+			StringPtrPtr3 ***string       `default:"hello"`
+			URLPtrPtr     **url.URL       `default:"https://example.com"`
+			BigIntPtrPtr  **big.Int       `default:"1234567890987654321"`
+			NestedPtrPtr  **Nested        `default:"dive"`
+			DurationPtr   **time.Duration `default:"1s"`
+		}
+		err := Struct(&foo)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, **foo.IntPtrPtr)
+		require.EqualValues(t, "hello", ***foo.StringPtrPtr3)
+		require.EqualValues(t, "https://example.com", (*foo.URLPtrPtr).String())
+		require.EqualValues(t, "1234567890987654321", (*foo.BigIntPtrPtr).String())
+		require.EqualValues(t, "world", (*foo.NestedPtrPtr).String)
+		require.EqualValues(t, time.Second, **foo.DurationPtr)
+	})
+	t.Run("not set", func(t *testing.T) {
+		var foo struct {
+			IntPtrPtr **int `default:"1"`
+		}
+		i := 10
+		p := &i
+		foo.IntPtrPtr = &p
+		err := Struct(&foo)
+		require.NoError(t, err)
+		require.EqualValues(t, 10, **foo.IntPtrPtr)
+	})
+	t.Run("allocate inner pointer", func(t *testing.T) {
+		var foo struct {
+			IntPtrPtr **int `default:"1"`
+		}
+		foo.IntPtrPtr = new(*int)
+		err := Struct(&foo)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, **foo.IntPtrPtr)
+	})
+}
+
 func TestStruct_Bool(t *testing.T) {
 	t.Run("set", func(t *testing.T) {
 		var boolT struct {
@@ -444,6 +516,22 @@ func TestStruct_BigIntPtr(t *testing.T) {
 	})
 }
 
+func TestStruct_BigIntValue(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		foo := &Foo{}
+		err := Struct(foo, WithSetters(append(DefaultSetters(), TextUnmarshalerValueSetter)...))
+		require.NoError(t, err)
+		require.EqualValues(t, "1234567890", foo.BigInt.String())
+	})
+	t.Run("not set", func(t *testing.T) {
+		foo := &Foo{}
+		foo.BigInt.SetInt64(9876543210)
+		err := Struct(foo, WithSetters(append(DefaultSetters(), TextUnmarshalerValueSetter)...))
+		require.NoError(t, err)
+		require.EqualValues(t, "9876543210", foo.BigInt.String())
+	})
+}
+
 func TestStruct_BigFloat(t *testing.T) {
 	t.Run("set", func(t *testing.T) {
 		foo := &Foo{}
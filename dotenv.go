@@ -0,0 +1,234 @@
+package go_default
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// WithDotEnv look up variables in dotenv files after the lookup set before, so the real environment wins
+//
+// Later files override earlier ones, and the files are read whenever Struct applies the source, so a missing file is
+// reported by Struct. The process environment is never modified.
+func WithDotEnv(names ...string) EnvOption {
+	return withDotEnv(names, false)
+}
+
+// WithDotEnvOverride look up variables in dotenv files before the lookup set before, so the files win
+func WithDotEnvOverride(names ...string) EnvOption {
+	return withDotEnv(names, true)
+}
+
+func withDotEnv(names []string, override bool) EnvOption {
+	return func(src *EnvSource) {
+		files := &dotEnvFiles{names: names}
+		src.loads = append(src.loads, files.read)
+		dotenv, lookup := files.lookup, src.LookupEnv
+		first, second := lookup, dotenv
+		if override {
+			first, second = dotenv, lookup
+		}
+		src.LookupEnv = func(name string) (string, bool) {
+			if value, ok := first(name); ok {
+				return value, true
+			}
+			return second(name)
+		}
+	}
+}
+
+// dotEnvFiles hold the values of dotenv files, read again whenever Struct applies the source
+type dotEnvFiles struct {
+	names  []string
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+func (f *dotEnvFiles) read() error {
+	values, err := ReadDotEnv(f.names...)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.values = values
+	return nil
+}
+
+func (f *dotEnvFiles) lookup(name string) (string, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	value, ok := f.values[name]
+	return value, ok
+}
+
+// ReadDotEnv read and merge dotenv files, later files override earlier ones
+func ReadDotEnv(names ...string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, fmt.Errorf("read dotenv file %s failed: %w", name, err)
+		}
+		fileValues, err := ParseDotEnv(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("parse dotenv file %s failed: %w", name, err)
+		}
+		for key, value := range fileValues {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+// ParseDotEnv parse a dotenv file
+//
+//   - blank lines and lines starting with "#" are ignored, an unquoted value ends at " #"
+//   - the "export " prefix is allowed, like "export KEY=value"
+//   - single-quoted values are literal, double-quoted values support "\n", "\t", "\"", "\\" and "\$"
+//   - quoted values can span multiple lines
+func ParseDotEnv(r io.Reader) (map[string]string, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &dotEnvParser{src: strings.ReplaceAll(string(b), "\r\n", "\n"), line: 1}
+	values := map[string]string{}
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return values, nil
+		}
+		key, value, err := p.entry()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", p.line, err)
+		}
+		values[key] = value
+	}
+}
+
+type dotEnvParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (p *dotEnvParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+// skipBlank skip whitespace, blank lines and comment lines
+func (p *dotEnvParser) skipBlank() {
+	for !p.eof() {
+		switch c := p.src[p.pos]; {
+		case c == '\n':
+			p.line++
+			p.pos++
+		case c == ' ' || c == '\t':
+			p.pos++
+		case c == '#':
+			p.skipLine()
+		default:
+			return
+		}
+	}
+}
+
+func (p *dotEnvParser) skipLine() {
+	for !p.eof() && p.src[p.pos] != '\n' {
+		p.pos++
+	}
+}
+
+func (p *dotEnvParser) skipSpaces() {
+	for !p.eof() && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *dotEnvParser) entry() (key, value string, err error) {
+	if strings.HasPrefix(p.src[p.pos:], "export ") {
+		p.pos += len("export ")
+		p.skipSpaces()
+	}
+	start := p.pos
+	for !p.eof() && isDotEnvKeyChar(p.src[p.pos]) {
+		p.pos++
+	}
+	key = p.src[start:p.pos]
+	if key == "" || !isEnvNameStart(key[0]) {
+		return "", "", fmt.Errorf("invalid variable name")
+	}
+	p.skipSpaces()
+	if p.eof() || p.src[p.pos] != '=' {
+		return "", "", fmt.Errorf("missing = after %s", key)
+	}
+	p.pos++
+	p.skipSpaces()
+
+	if !p.eof() && (p.src[p.pos] == '\'' || p.src[p.pos] == '"') {
+		if value, err = p.quoted(p.src[p.pos]); err != nil {
+			return "", "", fmt.Errorf("%s: %w", key, err)
+		}
+		p.skipSpaces()
+		if !p.eof() && p.src[p.pos] != '\n' && p.src[p.pos] != '#' {
+			return "", "", fmt.Errorf("%s: unexpected characters after quoted value", key)
+		}
+		p.skipLine()
+		return key, value, nil
+	}
+
+	start = p.pos
+	p.skipLine()
+	value = p.src[start:p.pos]
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = value[:i]
+	}
+	if i := strings.Index(value, "\t#"); i >= 0 {
+		value = value[:i]
+	}
+	return key, strings.TrimSpace(value), nil
+}
+
+// quoted parse a single or double quoted value, which can span multiple lines
+func (p *dotEnvParser) quoted(quote byte) (string, error) {
+	var b strings.Builder
+	p.pos++ // skip the opening quote
+	for !p.eof() {
+		c := p.src[p.pos]
+		p.pos++
+		switch {
+		case c == quote:
+			return b.String(), nil
+		case c == '\n':
+			p.line++
+			b.WriteByte(c)
+		case c == '\\' && quote == '"' && !p.eof():
+			escaped := p.src[p.pos]
+			p.pos++
+			switch escaped {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\', '$':
+				b.WriteByte(escaped)
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(escaped)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated quoted value")
+}
+
+func isDotEnvKeyChar(c byte) bool {
+	return isEnvNameChar(c) || c == '.' || c == '-'
+}
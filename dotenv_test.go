@@ -0,0 +1,101 @@
+package go_default
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	values, err := ParseDotEnv(strings.NewReader(`# comment
+PLAIN=value
+export EXPORTED = exported value
+EMPTY=
+INLINE=value # inline comment
+HASH=value#not-a-comment
+SINGLE='literal \n $HOME # not a comment'
+DOUBLE="escaped\tvalue \"quoted\" \$HOME"   # comment
+MULTI="line1
+line2"
+MULTI_SINGLE='a
+b'
+
+  INDENTED=yes
+WINDOWS=crlf` + "\r\n"))
+	require.NoError(t, err)
+	require.EqualValues(t, map[string]string{
+		"PLAIN":        "value",
+		"EXPORTED":     "exported value",
+		"EMPTY":        "",
+		"INLINE":       "value",
+		"HASH":         "value#not-a-comment",
+		"SINGLE":       `literal \n $HOME # not a comment`,
+		"DOUBLE":       "escaped\tvalue \"quoted\" $HOME",
+		"MULTI":        "line1\nline2",
+		"MULTI_SINGLE": "a\nb",
+		"INDENTED":     "yes",
+		"WINDOWS":      "crlf",
+	}, values)
+
+	t.Run("should return error with line number when line is malformed", func(t *testing.T) {
+		_, err := ParseDotEnv(strings.NewReader("A=1\nB\n"))
+		require.EqualError(t, err, "line 2: missing = after B")
+		_, err = ParseDotEnv(strings.NewReader("A=1\nB=\"open\n"))
+		require.EqualError(t, err, "line 3: B: unterminated quoted value")
+		_, err = ParseDotEnv(strings.NewReader("A='x' y\n"))
+		require.EqualError(t, err, "line 1: A: unexpected characters after quoted value")
+		_, err = ParseDotEnv(strings.NewReader("=x\n"))
+		require.EqualError(t, err, "line 1: invalid variable name")
+	})
+}
+
+func TestStruct_DotEnv(t *testing.T) {
+	dir := t.TempDir()
+	env := filepath.Join(dir, ".env")
+	local := filepath.Join(dir, ".env.local")
+	require.NoError(t, os.WriteFile(env, []byte("APP_SERVER_PORT=9000\nAPP_SERVER_HOST=dotenv.local\nAPP_NAME=\"svc\"\n"), 0600))
+	require.NoError(t, os.WriteFile(local, []byte("export APP_SERVER_PORT=9100\n"), 0600))
+	real := MapLookupEnv(map[string]string{"APP_SERVER_HOST": "real.local"})
+
+	t.Run("real environment wins", func(t *testing.T) {
+		var cfg EnvConfig
+		err := Struct(&cfg, WithEnv(WithEnvPrefix("APP"), WithEnvLookup(real), WithDotEnv(env, local)))
+		require.NoError(t, err)
+		require.EqualValues(t, "svc", cfg.Name)
+		require.EqualValues(t, 9100, cfg.Server.Port)
+		require.EqualValues(t, "real.local", cfg.Server.Host)
+	})
+	t.Run("dotenv wins", func(t *testing.T) {
+		var cfg EnvConfig
+		err := Struct(&cfg, WithEnv(WithEnvPrefix("APP"), WithEnvLookup(real), WithDotEnvOverride(env)))
+		require.NoError(t, err)
+		require.EqualValues(t, 9000, cfg.Server.Port)
+		require.EqualValues(t, "dotenv.local", cfg.Server.Host)
+	})
+	t.Run("process environment is not modified", func(t *testing.T) {
+		var cfg EnvConfig
+		err := Struct(&cfg, WithEnv(WithEnvPrefix("APP"), WithDotEnv(env)))
+		require.NoError(t, err)
+		require.EqualValues(t, 9000, cfg.Server.Port)
+		_, ok := os.LookupEnv("APP_SERVER_PORT")
+		require.False(t, ok)
+	})
+	t.Run("read when applied", func(t *testing.T) {
+		name := filepath.Join(t.TempDir(), ".env")
+		opt := WithEnv(WithEnvPrefix("APP"), WithDotEnv(name))
+		var cfg EnvConfig
+		require.ErrorContains(t, Struct(&cfg, opt), "read dotenv file")
+
+		require.NoError(t, os.WriteFile(name, []byte("APP_SERVER_PORT=9200\n"), 0600))
+		require.NoError(t, Struct(&cfg, opt))
+		require.EqualValues(t, 9200, cfg.Server.Port)
+	})
+	t.Run("should return error when file is missing", func(t *testing.T) {
+		var cfg EnvConfig
+		err := Struct(&cfg, WithEnv(WithDotEnv(filepath.Join(dir, "missing.env"))))
+		require.ErrorContains(t, err, "read dotenv file")
+	})
+}
@@ -0,0 +1,106 @@
+package go_default
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Integer is the set of types that can be registered as an enum
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+type enum struct {
+	values map[string]uint64
+	names  []string // sorted names, used in error messages
+}
+
+var (
+	enumsMu sync.RWMutex
+	enums   = map[reflect.Type]*enum{}
+)
+
+// RegisterEnum register the names of an enum type, so the default value can be written as a name, like "Warn"
+//
+// Names can be combined with "|" for bit flags, like "Read|Write". Registering the same type again replaces the names.
+func RegisterEnum[T Integer](values map[string]T) {
+	e := &enum{values: make(map[string]uint64, len(values))}
+	for name, value := range values {
+		e.values[name] = integerBits(reflect.ValueOf(value))
+		e.names = append(e.names, name)
+	}
+	sort.Strings(e.names)
+
+	enumsMu.Lock()
+	defer enumsMu.Unlock()
+	enums[reflect.TypeOf(*new(T))] = e
+}
+
+// RegisterEnumValues register the names of an enum type by the String method of its values
+func RegisterEnumValues[T interface {
+	Integer
+	fmt.Stringer
+}](values ...T) {
+	m := make(map[string]T, len(values))
+	for _, value := range values {
+		m[value.String()] = value
+	}
+	RegisterEnum(m)
+}
+
+// EnumSetter set the default value for types registered by RegisterEnum or RegisterEnumValues
+//
+// The value can be a name, names combined with "|", or a number, like "Warn", "Read|Write" or "2"
+func EnumSetter(path string, fieldValue reflect.Value, value string) (set bool, err error) {
+	enumsMu.RLock()
+	e, ok := enums[fieldValue.Type()]
+	enumsMu.RUnlock()
+	if !ok {
+		return false, nil
+	}
+
+	var bits uint64
+	for _, name := range strings.Split(value, "|") {
+		name = strings.TrimSpace(name)
+		if v, ok := e.values[name]; ok {
+			bits |= v
+			continue
+		}
+		v, err := parseIntegerBits(fieldValue.Type(), name)
+		if err != nil {
+			return false, fmt.Errorf("cannot set default value for %s, unknown %s name %s, valid names: %s", path, fieldValue.Type().String(), name, strings.Join(e.names, ", "))
+		}
+		bits |= v
+	}
+	switch fieldValue.Type().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fieldValue.SetInt(int64(bits))
+	default:
+		fieldValue.SetUint(bits)
+	}
+	return true, nil
+}
+
+func integerBits(v reflect.Value) uint64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(v.Int())
+	default:
+		return v.Uint()
+	}
+}
+
+func parseIntegerBits(t reflect.Type, value string) (uint64, error) {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, t.Bits())
+		return uint64(i), err
+	default:
+		return strconv.ParseUint(value, 10, t.Bits())
+	}
+}
@@ -0,0 +1,89 @@
+package go_default
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "Debug"
+	case LevelInfo:
+		return "Info"
+	case LevelWarn:
+		return "Warn"
+	default:
+		return "Unknown"
+	}
+}
+
+type Permission uint8
+
+const (
+	PermissionRead Permission = 1 << iota
+	PermissionWrite
+	PermissionExec
+)
+
+func init() {
+	RegisterEnumValues(LevelDebug, LevelInfo, LevelWarn)
+	RegisterEnum(map[string]Permission{
+		"Read":  PermissionRead,
+		"Write": PermissionWrite,
+		"Exec":  PermissionExec,
+	})
+}
+
+func TestStruct_Enum(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		var foo struct {
+			Level      Level       `default:"Warn"`
+			LevelNum   Level       `default:"1"`
+			LevelPtr   *Level      `default:"Info"`
+			Permission Permission  `default:"Read|Write"`
+			Spaced     Permission  `default:"Read | Exec"`
+			PermPtr    *Permission `default:"Write"`
+		}
+		err := Struct(&foo)
+		require.NoError(t, err)
+		require.EqualValues(t, LevelWarn, foo.Level)
+		require.EqualValues(t, LevelInfo, foo.LevelNum)
+		require.EqualValues(t, LevelInfo, *foo.LevelPtr)
+		require.EqualValues(t, PermissionRead|PermissionWrite, foo.Permission)
+		require.EqualValues(t, PermissionRead|PermissionExec, foo.Spaced)
+		require.EqualValues(t, PermissionWrite, *foo.PermPtr)
+	})
+	t.Run("not set", func(t *testing.T) {
+		var foo struct {
+			Level Level `default:"Warn"`
+		}
+		foo.Level = LevelInfo
+		err := Struct(&foo)
+		require.NoError(t, err)
+		require.EqualValues(t, LevelInfo, foo.Level)
+	})
+	t.Run("should return error with valid names when name is unknown", func(t *testing.T) {
+		var foo struct {
+			Level Level `default:"Wrn"`
+		}
+		err := Struct(&foo)
+		require.ErrorContains(t, err, "cannot set default value for Level, unknown go_default.Level name Wrn, valid names: Debug, Info, Warn")
+	})
+	t.Run("should return error when flag name is unknown", func(t *testing.T) {
+		var foo struct {
+			Permission Permission `default:"Read|Delete"`
+		}
+		err := Struct(&foo)
+		require.ErrorContains(t, err, "unknown go_default.Permission name Delete, valid names: Exec, Read, Write")
+	})
+}
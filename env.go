@@ -0,0 +1,307 @@
+package go_default
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// LookupEnvFunc look up the value of an environment variable, like os.LookupEnv
+type LookupEnvFunc func(name string) (string, bool)
+
+// WithExpandEnv expand environment variables in default values by os.LookupEnv, see ExpandEnv for the syntax
+func WithExpandEnv() Option {
+	return WithLookupEnv(os.LookupEnv)
+}
+
+// WithLookupEnv expand environment variables in default values by lookup, see ExpandEnv for the syntax
+//
+// It's useful for tests to inject variables without touching the process environment.
+func WithLookupEnv(lookup LookupEnvFunc) Option {
+	return func(cfg *Config) {
+		cfg.LookupEnv = lookup
+	}
+}
+
+// MapLookupEnv return a LookupEnvFunc that looks up variables in m
+func MapLookupEnv(m map[string]string) LookupEnvFunc {
+	return func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}
+}
+
+// ExpandEnv expand environment variables in s by lookup
+//
+//   - "$NAME" and "${NAME}" are replaced by the value, or an empty string if it's unset
+//   - "${NAME:-word}" use word if NAME is unset or empty, "${NAME-word}" only if it's unset
+//   - "${NAME:?message}" fail with message if NAME is unset or empty, "${NAME?message}" only if it's unset
+//   - "$$" is a literal "$", and a "$" not followed by a name or "{" is kept as is
+//
+// The word of a default may contain expansions too, like "${A:-${B:-c}}".
+func ExpandEnv(s string, lookup LookupEnvFunc) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch next := s[i+1]; {
+		case next == '$':
+			b.WriteByte('$')
+			i++
+		case next == '{':
+			end := matchingBrace(s, i+1)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable %s", s[i:])
+			}
+			value, err := expandBraced(s[i+2:end], lookup)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			i = end
+		case isEnvNameStart(next):
+			end := i + 2
+			for end < len(s) && isEnvNameChar(s[end]) {
+				end++
+			}
+			value, _ := lookup(s[i+1 : end])
+			b.WriteString(value)
+			i = end - 1
+		default:
+			b.WriteByte('$')
+		}
+	}
+	return b.String(), nil
+}
+
+// expandBraced expand the content between "${" and "}"
+func expandBraced(s string, lookup LookupEnvFunc) (string, error) {
+	end := 0
+	for end < len(s) && isEnvNameChar(s[end]) {
+		end++
+	}
+	name, op := s[:end], s[end:]
+	if name == "" || !isEnvNameStart(name[0]) {
+		return "", fmt.Errorf("invalid variable name in ${%s}", s)
+	}
+	value, ok := lookup(name)
+
+	colon := strings.HasPrefix(op, ":")
+	if colon {
+		op = op[1:]
+	}
+	unset := !ok || colon && value == ""
+	switch {
+	case op == "" && !colon:
+		return value, nil
+	case strings.HasPrefix(op, "-"):
+		if unset {
+			return ExpandEnv(op[1:], lookup)
+		}
+		return value, nil
+	case strings.HasPrefix(op, "?"):
+		if unset {
+			message, err := ExpandEnv(op[1:], lookup)
+			if err != nil {
+				return "", err
+			}
+			if message == "" {
+				message = "required variable is not set"
+			}
+			return "", fmt.Errorf("%s: %s", name, message)
+		}
+		return value, nil
+	default:
+		return "", fmt.Errorf("invalid variable expression ${%s}", s)
+	}
+}
+
+// matchingBrace return the index of the "}" that closes the "{" at open, or -1
+func matchingBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isEnvNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isEnvNameChar(c byte) bool {
+	return isEnvNameStart(c) || c >= '0' && c <= '9'
+}
+
+// EnvSource derive environment variable names from field paths, like "Server.Port" to "APP_SERVER_PORT"
+type EnvSource struct {
+	Name      string                      // name of the source in the Provenance, "env" by default
+	Prefix    string                      // prefix of every name, like "APP"
+	Separator string                      // separator between the prefix and the path segments, "_" by default
+	Mangle    func(segment string) string // convert a path segment to a name segment, strings.ToUpper by default
+	TagName   string                      // tag to set the full name of a field, "env" by default
+	LookupEnv LookupEnvFunc               // look up the variables, os.LookupEnv by default
+
+	loads []func() error // read the files of the options, like dotenv files, whenever Struct applies the source
+}
+
+// EnvOption configure an EnvSource
+type EnvOption func(src *EnvSource)
+
+// WithEnvPrefix set the prefix of every name, like "APP"
+func WithEnvPrefix(prefix string) EnvOption {
+	return func(src *EnvSource) {
+		src.Prefix = prefix
+	}
+}
+
+// WithEnvSeparator set the separator between the prefix and the path segments
+func WithEnvSeparator(separator string) EnvOption {
+	return func(src *EnvSource) {
+		src.Separator = separator
+	}
+}
+
+// WithEnvMangle set the function to convert a path segment to a name segment, like UpperSnakeCase
+func WithEnvMangle(mangle func(segment string) string) EnvOption {
+	return func(src *EnvSource) {
+		src.Mangle = mangle
+	}
+}
+
+// WithEnvTagName set the tag to set the full name of a field
+func WithEnvTagName(tagName string) EnvOption {
+	return func(src *EnvSource) {
+		src.TagName = tagName
+	}
+}
+
+// WithEnvLookup set the function to look up the variables
+func WithEnvLookup(lookup LookupEnvFunc) EnvOption {
+	return func(src *EnvSource) {
+		src.LookupEnv = lookup
+	}
+}
+
+// NewEnvSource create an EnvSource
+func NewEnvSource(opts ...EnvOption) *EnvSource {
+	src := &EnvSource{
+		Name:      "env",
+		Separator: "_",
+		Mangle:    strings.ToUpper,
+		TagName:   "env",
+		LookupEnv: os.LookupEnv,
+	}
+	for _, opt := range opts {
+		opt(src)
+	}
+	return src
+}
+
+// WithEnv override the tag values by environment variables named after the field paths
+//
+// The variables are converted by the same setters as the tag values, and take precedence over them.
+// A field can set its full variable name by the env tag, like `env:"PORT"`. The fields without a default value are
+// only looked up by their env tag.
+func WithEnv(opts ...EnvOption) Option {
+	return WithSources(NewEnvSource(opts...))
+}
+
+// VarName return the environment variable name of a path, like "APP_SERVER_PORT" for "Server.Port"
+//
+// An index is a segment of its own, like "APP_SERVERS_0_PORT" for "Servers[0].Port".
+func (src *EnvSource) VarName(path string) string {
+	var segments []string
+	if steps, err := parsePath(path); err == nil {
+		for _, step := range steps {
+			if step.isIndex {
+				segments = append(segments, step.index)
+			} else {
+				segments = append(segments, step.name)
+			}
+		}
+	} else {
+		segments = strings.Split(path, ".")
+	}
+	names := make([]string, 0, len(segments)+1)
+	if src.Prefix != "" {
+		names = append(names, src.Prefix)
+	}
+	for _, segment := range segments {
+		names = append(names, src.Mangle(segment))
+	}
+	return strings.Join(names, src.Separator)
+}
+
+// Lookup look up the variable named after the path
+func (src *EnvSource) Lookup(path string) (string, bool) {
+	return src.LookupEnv(src.VarName(path))
+}
+
+// LookupField look up the variable named by the env tag of the field, or named after the path
+func (src *EnvSource) LookupField(path string, field reflect.StructField) (string, bool) {
+	return src.lookupDefaulted(path, field, true)
+}
+
+// lookupDefaulted look up the variable named by the env tag of the field, or named after the path if the field has a
+// default value, so unrelated variables like HOME or PATH don't override the untagged fields
+func (src *EnvSource) lookupDefaulted(path string, field reflect.StructField, hasDefault bool) (string, bool) {
+	name, ok := field.Tag.Lookup(src.TagName)
+	if !ok || name == "" {
+		if !hasDefault {
+			return "", false
+		}
+		name = src.VarName(path)
+	}
+	return src.LookupEnv(name)
+}
+
+func (src *EnvSource) String() string {
+	return src.Name
+}
+
+// load read the files of the options, like dotenv files, so Struct sees their current contents
+func (src *EnvSource) load() error {
+	for _, load := range src.loads {
+		if err := load(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpperSnakeCase convert a path segment to upper snake case, like "MaxConns" to "MAX_CONNS" and "HTTPPort" to "HTTP_PORT"
+func UpperSnakeCase(segment string) string {
+	var b strings.Builder
+	for i := 0; i < len(segment); i++ {
+		c := segment[i]
+		if i > 0 && isUpper(c) && (!isUpper(segment[i-1]) || i+1 < len(segment) && isLower(segment[i+1])) {
+			b.WriteByte('_')
+		}
+		b.WriteString(strings.ToUpper(string(c)))
+	}
+	return b.String()
+}
+
+func isUpper(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
+
+func isLower(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
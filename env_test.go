@@ -0,0 +1,228 @@
+package go_default
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandEnv(t *testing.T) {
+	lookup := MapLookupEnv(map[string]string{
+		"HOSTNAME": "node-1",
+		"DATA_DIR": "/data",
+		"EMPTY":    "",
+	})
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "plain", want: "plain"},
+		{value: "$HOSTNAME", want: "node-1"},
+		{value: "${HOSTNAME}.local", want: "node-1.local"},
+		{value: "$HOSTNAME-$MISSING.", want: "node-1-."},
+		{value: "${DATA_DIR:-/var/lib/app}/cache", want: "/data/cache"},
+		{value: "${MISSING:-/var/lib/app}/cache", want: "/var/lib/app/cache"},
+		{value: "${EMPTY:-fallback}", want: "fallback"},
+		{value: "${EMPTY-fallback}", want: ""},
+		{value: "${MISSING-fallback}", want: "fallback"},
+		{value: "${MISSING:-${DATA_DIR}/tmp}", want: "/data/tmp"},
+		{value: "${DATA_DIR:?required}", want: "/data"},
+		{value: "$$HOSTNAME costs $5 and 100$", want: "$HOSTNAME costs $5 and 100$"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			value, err := ExpandEnv(tt.value, lookup)
+			require.NoError(t, err)
+			require.EqualValues(t, tt.want, value)
+		})
+	}
+
+	t.Run("should return error when variable is required", func(t *testing.T) {
+		_, err := ExpandEnv("${MISSING:?set MISSING to the data dir}", lookup)
+		require.EqualError(t, err, "MISSING: set MISSING to the data dir")
+		_, err = ExpandEnv("${EMPTY:?}", lookup)
+		require.EqualError(t, err, "EMPTY: required variable is not set")
+		_, err = ExpandEnv("${EMPTY?}", lookup)
+		require.NoError(t, err)
+	})
+	t.Run("should return error when expression is malformed", func(t *testing.T) {
+		_, err := ExpandEnv("${HOSTNAME", lookup)
+		require.EqualError(t, err, "unterminated variable ${HOSTNAME")
+		_, err = ExpandEnv("${1X}", lookup)
+		require.EqualError(t, err, "invalid variable name in ${1X}")
+		_, err = ExpandEnv("${HOSTNAME/x}", lookup)
+		require.EqualError(t, err, "invalid variable expression ${HOSTNAME/x}")
+	})
+}
+
+func TestStruct_ExpandEnv(t *testing.T) {
+	lookup := MapLookupEnv(map[string]string{
+		"HOSTNAME": "node-1",
+		"PORT":     "9090",
+		"TIMEOUT":  "5s",
+	})
+
+	t.Run("set", func(t *testing.T) {
+		var foo struct {
+			Host     string   `default:"$HOSTNAME"`
+			Cache    string   `default:"${DATA_DIR:-/var/lib/app}/cache"`
+			Port     int      `default:"${PORT}"`
+			Nested   *Nested  `default:"dive"`
+			Hosts    []string `default:"$HOSTNAME,backup,sep=','"`
+			Literal  string   `default:"$$HOSTNAME"`
+			Quoted   string   `default:"'${HOSTNAME}\\n'"`
+			Untagged string
+		}
+		err := Struct(&foo, WithLookupEnv(lookup))
+		require.NoError(t, err)
+		require.EqualValues(t, "node-1", foo.Host)
+		require.EqualValues(t, "/var/lib/app/cache", foo.Cache)
+		require.EqualValues(t, 9090, foo.Port)
+		require.EqualValues(t, "world", foo.Nested.String)
+		require.EqualValues(t, []string{"node-1", "backup"}, foo.Hosts)
+		require.EqualValues(t, "$HOSTNAME", foo.Literal)
+		require.EqualValues(t, "node-1\n", foo.Quoted)
+		require.EqualValues(t, "", foo.Untagged)
+	})
+	t.Run("not expanded without option", func(t *testing.T) {
+		var foo struct {
+			Host string `default:"$HOSTNAME"`
+		}
+		err := Struct(&foo)
+		require.NoError(t, err)
+		require.EqualValues(t, "$HOSTNAME", foo.Host)
+	})
+	t.Run("process environment", func(t *testing.T) {
+		t.Setenv("GO_DEFAULT_TEST_PORT", "8081")
+		var foo struct {
+			Port int `default:"${GO_DEFAULT_TEST_PORT:-80}"`
+		}
+		err := Struct(&foo, WithExpandEnv())
+		require.NoError(t, err)
+		require.EqualValues(t, 8081, foo.Port)
+	})
+	t.Run("should return error when required variable is unset", func(t *testing.T) {
+		var foo struct {
+			Secret string `default:"${SECRET:?secret is required}"`
+		}
+		err := Struct(&foo, WithLookupEnv(lookup))
+		require.ErrorContains(t, err, "cannot set default value for Secret, expand ${SECRET:?secret is required} failed: SECRET: secret is required")
+	})
+}
+
+type EnvServer struct {
+	Host     string        `default:"localhost"`
+	Port     int           `default:"8080"`
+	MaxConns int           `default:"10"`
+	Timeout  time.Duration `default:"1s"`
+	Tags     []string      `default:"a,sep=','"`
+	Token    string        `env:"SERVICE_TOKEN"`
+}
+
+type EnvConfig struct {
+	Name   string     `default:"app"`
+	Server EnvServer  `default:"dive"`
+	Backup *EnvServer `default:"dive"`
+	Skip   string     `default:"-"`
+}
+
+func TestStruct_Env(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		lookup := MapLookupEnv(map[string]string{
+			"APP_NAME":            "svc",
+			"APP_SERVER_PORT":     "9000",
+			"APP_SERVER_TIMEOUT":  "5s",
+			"APP_SERVER_TAGS":     "x,y",
+			"APP_BACKUP_HOST":     "backup.local",
+			"APP_SKIP":            "ignored",
+			"SERVICE_TOKEN":       "secret",
+			"APP_SERVER_MAXCONNS": "20",
+		})
+		var cfg EnvConfig
+		err := Struct(&cfg, WithEnv(WithEnvPrefix("APP"), WithEnvLookup(lookup)))
+		require.NoError(t, err)
+		require.EqualValues(t, "svc", cfg.Name)
+		require.EqualValues(t, EnvServer{
+			Host:     "localhost",
+			Port:     9000,
+			MaxConns: 20,
+			Timeout:  5 * time.Second,
+			Tags:     []string{"x", "y"},
+			Token:    "secret",
+		}, cfg.Server)
+		require.EqualValues(t, "backup.local", cfg.Backup.Host)
+		require.EqualValues(t, 8080, cfg.Backup.Port)
+		require.EqualValues(t, "secret", cfg.Backup.Token)
+		require.EqualValues(t, "", cfg.Skip)
+	})
+	t.Run("override non-zero value", func(t *testing.T) {
+		lookup := MapLookupEnv(map[string]string{"SERVER_PORT": "9000"})
+		cfg := EnvConfig{Server: EnvServer{Port: 1, Host: "example.com"}}
+		err := Struct(&cfg, WithEnv(WithEnvLookup(lookup)))
+		require.NoError(t, err)
+		require.EqualValues(t, 9000, cfg.Server.Port)
+		require.EqualValues(t, "example.com", cfg.Server.Host)
+	})
+	t.Run("custom separator and mangle", func(t *testing.T) {
+		lookup := MapLookupEnv(map[string]string{"app__server__max_conns": "30"})
+		var cfg EnvConfig
+		err := Struct(&cfg, WithEnv(
+			WithEnvPrefix("app"),
+			WithEnvSeparator("__"),
+			WithEnvMangle(func(segment string) string { return strings.ToLower(UpperSnakeCase(segment)) }),
+			WithEnvLookup(lookup),
+		))
+		require.NoError(t, err)
+		require.EqualValues(t, 30, cfg.Server.MaxConns)
+	})
+	t.Run("untagged fields", func(t *testing.T) {
+		lookup := MapLookupEnv(map[string]string{
+			"PATH":         "/usr/bin",
+			"SERVER":       "x",
+			"SERVER_HOST":  "example.com",
+			"SERVER_TOKEN": "ignored",
+		})
+		var cfg struct {
+			Path   string
+			Server EnvServer
+			Port   int `env:"PORT" default:"80"`
+		}
+		err := Struct(&cfg, WithEnv(WithEnvLookup(lookup)))
+		require.NoError(t, err)
+		require.Empty(t, cfg.Path)
+		require.Empty(t, cfg.Server.Host)
+		require.EqualValues(t, 80, cfg.Port)
+	})
+	t.Run("should return error when failed to parse value", func(t *testing.T) {
+		lookup := MapLookupEnv(map[string]string{"SERVER_PORT": "http"})
+		var cfg EnvConfig
+		err := Struct(&cfg, WithEnv(WithEnvLookup(lookup)))
+		require.ErrorContains(t, err, "cannot set default value for Server.Port, parse http to int failed")
+	})
+}
+
+func TestEnvSource_VarName(t *testing.T) {
+	src := NewEnvSource(WithEnvPrefix("APP"))
+	require.EqualValues(t, "APP_SERVER_PORT", src.VarName("Server.Port"))
+	require.EqualValues(t, "APP_SERVER_MAXCONNS", src.VarName("Server.MaxConns"))
+	require.EqualValues(t, "APP_SERVERS_0_PORT", src.VarName("Servers[0].Port"))
+	require.EqualValues(t, "APP_LIMITS_API_MAX", src.VarName("Limits[api].Max"))
+
+	var cfg struct {
+		Servers []EnvServer `default:"dive"`
+	}
+	cfg.Servers = make([]EnvServer, 2)
+	lookup := func(name string) (string, bool) {
+		return "9000", name == "SERVERS_1_PORT"
+	}
+	require.NoError(t, Struct(&cfg, WithEnv(WithEnvLookup(lookup))))
+	require.EqualValues(t, 8080, cfg.Servers[0].Port)
+	require.EqualValues(t, 9000, cfg.Servers[1].Port)
+
+	src = NewEnvSource(WithEnvMangle(UpperSnakeCase))
+	require.EqualValues(t, "SERVER_MAX_CONNS", src.VarName("Server.MaxConns"))
+	require.EqualValues(t, "HTTP_PORT", src.VarName("HTTPPort"))
+	require.EqualValues(t, "TLS_CERT_FILE", src.VarName("TLSCertFile"))
+}
@@ -0,0 +1,135 @@
+package go_default
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PathPrefix marks a string default value as a filesystem path that should be expanded, like "path:~/.config/app"
+const PathPrefix = "path:"
+
+// FileModeSetter set the default value for fs.FileMode (and os.FileMode)
+//
+// The value can be an octal number or a symbolic permission string, like "0644", "0o755", "rwxr-x---" or "drwxr-xr-x"
+func FileModeSetter(path string, fieldValue reflect.Value, value string) (set bool, err error) {
+	if fieldValue.Type() != reflect.TypeOf(fs.FileMode(0)) {
+		return false, nil
+	}
+	mode, err := ParseFileMode(value)
+	if err != nil {
+		return false, fmt.Errorf("cannot set default value for %s, parse %s to %s failed", path, value, fieldValue.Type().String())
+	}
+	fieldValue.Set(reflect.ValueOf(mode))
+	return true, nil
+}
+
+// PathSetter set the default value for string fields whose value starts with PathPrefix
+//
+// The prefix is stripped and the rest is expanded by ExpandPath, like "path:~/.config/app" or "path:{UserCacheDir}/app"
+func PathSetter(path string, fieldValue reflect.Value, value string) (set bool, err error) {
+	if fieldValue.Type().Kind() != reflect.String || !strings.HasPrefix(value, PathPrefix) {
+		return false, nil
+	}
+	p, err := ExpandPath(strings.TrimPrefix(value, PathPrefix))
+	if err != nil {
+		return false, fmt.Errorf("cannot set default value for %s, expand path %s failed: %w", path, value, err)
+	}
+	fieldValue.SetString(p)
+	return true, nil
+}
+
+// ParseFileMode parse an octal or symbolic permission string to fs.FileMode
+//
+//   - octal: "644", "0644" or "0o644"
+//   - symbolic: "rwxr-x---", optionally prefixed by "-" for a regular file or "d" for a directory
+func ParseFileMode(value string) (fs.FileMode, error) {
+	switch len(value) {
+	case 9:
+		if mode, ok := parseSymbolicPerm(value); ok {
+			return mode, nil
+		}
+	case 10:
+		if mode, ok := parseSymbolicPerm(value[1:]); ok {
+			switch value[0] {
+			case '-':
+				return mode, nil
+			case 'd':
+				return mode | fs.ModeDir, nil
+			}
+		}
+	}
+	octal := value
+	if strings.HasPrefix(octal, "0o") || strings.HasPrefix(octal, "0O") {
+		octal = octal[2:]
+	}
+	mode, err := strconv.ParseUint(octal, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid file mode %q", value)
+	}
+	return fs.FileMode(mode), nil
+}
+
+func parseSymbolicPerm(value string) (fs.FileMode, bool) {
+	const rwx = "rwxrwxrwx"
+	var mode fs.FileMode
+	for i := 0; i < len(rwx); i++ {
+		switch value[i] {
+		case rwx[i]:
+			mode |= 1 << uint(len(rwx)-1-i)
+		case '-':
+		default:
+			return 0, false
+		}
+	}
+	return mode, true
+}
+
+var homeVarRegexp = regexp.MustCompile(`\$\{HOME\}|\$HOME\b`)
+
+// ExpandPath expand the home directory and well-known user directories in a path
+//
+//   - "~" or "~/..." is replaced by os.UserHomeDir
+//   - "$HOME" and "${HOME}" are replaced by os.UserHomeDir
+//   - "{UserHomeDir}", "{UserConfigDir}", "{UserCacheDir}" and "{TempDir}" are replaced by the matching os function
+func ExpandPath(p string) (string, error) {
+	placeholders := []struct {
+		name    string
+		resolve func() (string, error)
+	}{
+		{"{UserHomeDir}", os.UserHomeDir},
+		{"{UserConfigDir}", os.UserConfigDir},
+		{"{UserCacheDir}", os.UserCacheDir},
+		{"{TempDir}", func() (string, error) { return os.TempDir(), nil }},
+	}
+
+	if p == "~" || strings.HasPrefix(p, "~/") || strings.HasPrefix(p, "~"+string(os.PathSeparator)) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		p = home + p[1:]
+	}
+	if homeVarRegexp.MatchString(p) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		p = homeVarRegexp.ReplaceAllLiteralString(p, home)
+	}
+	for _, placeholder := range placeholders {
+		if !strings.Contains(p, placeholder.name) {
+			continue
+		}
+		dir, err := placeholder.resolve()
+		if err != nil {
+			return "", err
+		}
+		p = strings.ReplaceAll(p, placeholder.name, dir)
+	}
+	return p, nil
+}
@@ -0,0 +1,98 @@
+package go_default
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStruct_FileMode(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		var foo struct {
+			Octal      fs.FileMode  `default:"0644"`
+			OctalNoPad os.FileMode  `default:"755"`
+			OctalO     fs.FileMode  `default:"0o600"`
+			Symbolic   fs.FileMode  `default:"rwxr-x---"`
+			Dir        fs.FileMode  `default:"drwxr-xr-x"`
+			ModePtr    *fs.FileMode `default:"0640"`
+		}
+		err := Struct(&foo)
+		require.NoError(t, err)
+		require.EqualValues(t, fs.FileMode(0644), foo.Octal)
+		require.EqualValues(t, fs.FileMode(0755), foo.OctalNoPad)
+		require.EqualValues(t, fs.FileMode(0600), foo.OctalO)
+		require.EqualValues(t, fs.FileMode(0750), foo.Symbolic)
+		require.EqualValues(t, fs.ModeDir|0755, foo.Dir)
+		require.EqualValues(t, fs.FileMode(0640), *foo.ModePtr)
+	})
+	t.Run("not set", func(t *testing.T) {
+		var foo struct {
+			Mode fs.FileMode `default:"0644"`
+		}
+		foo.Mode = 0600
+		err := Struct(&foo)
+		require.NoError(t, err)
+		require.EqualValues(t, fs.FileMode(0600), foo.Mode)
+	})
+	t.Run("should return error when failed to parse file mode", func(t *testing.T) {
+		var foo struct {
+			Mode fs.FileMode `default:"0899"`
+		}
+		err := Struct(&foo)
+		require.ErrorContains(t, err, "cannot set default value for Mode, parse 0899 to fs.FileMode failed")
+	})
+}
+
+func TestStruct_Path(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	cacheDir, err := os.UserCacheDir()
+	require.NoError(t, err)
+
+	t.Run("set", func(t *testing.T) {
+		var foo struct {
+			Tilde    string  `default:"path:~/.config/app"`
+			HomeVar  string  `default:"path:$HOME/app"`
+			Cache    string  `default:"path:{UserCacheDir}/app"`
+			Temp     string  `default:"path:{TempDir}"`
+			PathPtr  *string `default:"path:${HOME}"`
+			Verbatim string  `default:"~/not/expanded"`
+		}
+		err := Struct(&foo)
+		require.NoError(t, err)
+		require.EqualValues(t, home+"/.config/app", foo.Tilde)
+		require.EqualValues(t, home+"/app", foo.HomeVar)
+		require.EqualValues(t, filepath.Join(cacheDir, "app"), foo.Cache)
+		require.EqualValues(t, os.TempDir(), foo.Temp)
+		require.EqualValues(t, home, *foo.PathPtr)
+		require.EqualValues(t, "~/not/expanded", foo.Verbatim)
+	})
+	t.Run("not set", func(t *testing.T) {
+		var foo struct {
+			Path string `default:"path:~/.config/app"`
+		}
+		foo.Path = "/etc/app"
+		err := Struct(&foo)
+		require.NoError(t, err)
+		require.EqualValues(t, "/etc/app", foo.Path)
+	})
+}
+
+func TestExpandPath(t *testing.T) {
+	t.Setenv("HOME", "/home/gopher")
+
+	p, err := ExpandPath("~")
+	require.NoError(t, err)
+	require.EqualValues(t, "/home/gopher", p)
+
+	p, err = ExpandPath("$HOMEDIR/$HOME")
+	require.NoError(t, err)
+	require.EqualValues(t, "$HOMEDIR//home/gopher", p)
+
+	p, err = ExpandPath("/opt/~user")
+	require.NoError(t, err)
+	require.EqualValues(t, "/opt/~user", p)
+}
@@ -0,0 +1,211 @@
+package go_default
+
+import (
+	"encoding"
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RegisterFlags fill the default values of input, then register one flag per leaf field on fs
+//
+// The flags are named after the paths like "server.port", or by the flag tag of the field, and described by the usage
+// tag. The filled value is the default of the flag. Parsing fs converts the values by the same setters as the tag
+// values and writes them into input, so defaults, help and parsing stay in sync. The fields defaulting to other fields,
+// like `$ref:Server.Port`, follow the flags setting the referenced fields, unless set by a flag themselves.
+func RegisterFlags(fs *flag.FlagSet, input any, opts ...Option) error {
+	cfg := newConfig(opts...)
+	if err := cfg.fill(input); err != nil {
+		return err
+	}
+	root := reflect.ValueOf(input)
+	return registerFlags(fs, "", root, root, nil, cfg)
+}
+
+func registerFlags(fs *flag.FlagSet, deepName string, root, value reflect.Value, pending *pendingStruct, cfg *Config) error {
+	t := value.Type().Elem()
+	cfg.diving = append(cfg.diving, t)
+	defer func() { cfg.diving = cfg.diving[:len(cfg.diving)-1] }()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !isEmbeddedStruct(field) {
+			continue
+		}
+		fieldValue := value.Elem().Field(i)
+		path := path(deepName, field.Name)
+
+		tagValue := tagValueOf(t, field, cfg)
+		if cfg.isAutoDive(field, fieldValue, tagValue) {
+			tagValue = "dive"
+		}
+		tag, err := ParseTag(tagValue)
+		if err != nil {
+			return fmt.Errorf("cannot register flag for %s, parse tag %s failed: %w", path, tagValue, err)
+		}
+		if tag.IsSkip() {
+			continue
+		}
+		if tag.IsDive() && isStruct(fieldValue.Type()) {
+			for fieldValue.Type().Kind() == reflect.Pointer && !fieldValue.IsNil() {
+				fieldValue = fieldValue.Elem()
+			}
+			next := pending
+			if fieldValue.Type().Kind() == reflect.Pointer {
+				// left nil by the allocation policy, so the flags are bound to a filled struct allocated on first use
+				if cfg.isDiving(fieldValue.Type()) {
+					continue // a recursive type
+				}
+				if next, err = newPendingStruct(path, fieldValue, pending, cfg); err != nil {
+					return err
+				}
+				fieldValue = next.value
+				for fieldValue.Type().Kind() == reflect.Pointer {
+					fieldValue = fieldValue.Elem()
+				}
+			}
+			if err := registerFlags(fs, path, root, fieldValue.Addr(), next, cfg); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !field.IsExported() || !acceptsValue(path, fieldValue, formatValue(fieldValue), cfg) {
+			continue // a nested struct without dive, only filled by the setters
+		}
+		name := field.Tag.Get("flag")
+		if name == "" {
+			name = FlagName(path)
+		}
+		if fs.Lookup(name) != nil {
+			return fmt.Errorf("cannot register flag for %s, flag %s already defined", path, name)
+		}
+		defValue := ""
+		if !fieldValue.IsZero() {
+			// the filled value, so the resolved references, paths and variables are shown rather than the tag
+			defValue = formatField(fieldValue, tag.Options)
+		}
+		f := &fieldFlag{path: path, root: root, value: fieldValue, pending: pending, options: tag.Options, cfg: cfg}
+		var v flag.Value = f
+		if fieldValue.Type().Kind() == reflect.Bool {
+			v = &boolFieldFlag{f}
+		}
+		fs.Var(v, name, field.Tag.Get("usage"))
+		fs.Lookup(name).DefValue = defValue
+	}
+	return nil
+}
+
+// pendingStruct is a nil pointer to a struct, set to a filled struct by the first flag set under it
+type pendingStruct struct {
+	field  reflect.Value // the nil pointer
+	value  reflect.Value // the filled struct, behind pointers of the type of field
+	parent *pendingStruct
+}
+
+// newPendingStruct fill a struct for a nil pointer without touching the pointer, nor the provenance and the
+// validations of cfg
+func newPendingStruct(path string, field reflect.Value, parent *pendingStruct, cfg *Config) (*pendingStruct, error) {
+	scratch := *cfg
+	scratch.provenance, scratch.diving, scratch.refs, scratch.resolved, scratch.validators = nil, nil, nil, nil, nil
+	value := reflect.New(field.Type()).Elem()
+	if err := diveStruct(path, value, Tag{Options: map[string]string{"alloc": "always"}}, &scratch); err != nil {
+		return nil, err
+	}
+	return &pendingStruct{field: field, value: value, parent: parent}, nil
+}
+
+// alloc set the pointers from the top level one
+func (p *pendingStruct) alloc() {
+	if p == nil {
+		return
+	}
+	p.parent.alloc()
+	if p.field.IsNil() {
+		p.field.Set(p.value)
+	}
+}
+
+// fieldFlag is a flag.Value that converts the value by the setters into a field
+type fieldFlag struct {
+	path    string
+	root    reflect.Value // the registered struct, to validate it after a nested struct is set
+	value   reflect.Value
+	pending *pendingStruct // the nil pointer the field is under, if any
+	options map[string]string
+	cfg     *Config
+}
+
+func (f *fieldFlag) String() string {
+	if f == nil || !f.value.IsValid() {
+		return ""
+	}
+	return formatField(f.value, f.options)
+}
+
+func (f *fieldFlag) Set(s string) error {
+	s, err := resolve(f.path, s, f.cfg)
+	if err != nil {
+		return err
+	}
+	f.pending.alloc()
+	f.value.Set(reflect.Zero(f.value.Type()))
+	if err := fillSome(f.path, f.value, Tag{Value: s, Quoted: true, Options: f.options}, f.cfg); err != nil {
+		return err
+	}
+	f.cfg.record(f.path, "flags")
+	// resolve the references again, but the ones overlapping the field, now set by the flag
+	refs := f.cfg.resolved[:0]
+	for _, r := range f.cfg.resolved {
+		if r.path != f.path && !isSubpath(r.path, f.path) && !isSubpath(f.path, r.path) {
+			refs = append(refs, r)
+		}
+	}
+	f.cfg.refs, f.cfg.resolved = refs, nil
+	return f.cfg.finish(f.root)
+}
+
+type boolFieldFlag struct {
+	*fieldFlag
+}
+
+func (f *boolFieldFlag) IsBoolFlag() bool {
+	return true
+}
+
+// formatField format a field value like its tag value, joining the elements by the sep option if any
+func formatField(v reflect.Value, options map[string]string) string {
+	sep, ok := options["sep"]
+	if !ok || v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return formatValue(v)
+	}
+	elems := make([]string, v.Len())
+	for i := range elems {
+		elems[i] = formatValue(v.Index(i))
+	}
+	return strings.Join(elems, sep)
+}
+
+// formatValue format a field value, by its String or MarshalText method if any
+func formatValue(v reflect.Value) string {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		if stringer, ok := v.Interface().(fmt.Stringer); ok {
+			return stringer.String()
+		}
+		v = v.Elem()
+	}
+	switch value := v.Interface().(type) {
+	case fmt.Stringer:
+		return value.String()
+	case encoding.TextMarshaler:
+		b, err := value.MarshalText()
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,137 @@
+package go_default
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type FlagServer struct {
+	Host    string        `default:"localhost" usage:"host to listen on"`
+	Port    int           `default:"8080" usage:"port to listen on"`
+	Timeout time.Duration `default:"1s"`
+	Tags    []string      `default:"a;b,sep=';'"`
+}
+
+type FlagConfig struct {
+	Name    string      `default:"app" flag:"name"`
+	Debug   bool        `usage:"enable debug logs"`
+	Level   *int        `default:"1"`
+	Server  FlagServer  `default:"dive"`
+	Backup  *FlagServer `default:"dive"`
+	Skip    string      `default:"-"`
+	Admin   int         `default:"$ref:Server.Port"`
+	Other   FlagServer
+	private string
+}
+
+func TestRegisterFlags(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		var cfg FlagConfig
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		require.NoError(t, RegisterFlags(fs, &cfg))
+		require.NoError(t, fs.Parse(nil))
+		require.EqualValues(t, "app", cfg.Name)
+		require.EqualValues(t, 8080, cfg.Server.Port)
+		require.EqualValues(t, 8080, cfg.Backup.Port)
+		require.EqualValues(t, []string{"a", "b"}, cfg.Server.Tags)
+
+		var names []string
+		fs.VisitAll(func(f *flag.Flag) {
+			names = append(names, f.Name)
+		})
+		require.EqualValues(t, []string{
+			"admin", "backup.host", "backup.port", "backup.tags", "backup.timeout",
+			"debug", "level", "name",
+			"server.host", "server.port", "server.tags", "server.timeout",
+		}, names)
+
+		port := fs.Lookup("server.port")
+		require.EqualValues(t, "8080", port.DefValue)
+		require.EqualValues(t, "port to listen on", port.Usage)
+		require.EqualValues(t, "8080", port.Value.String())
+		require.EqualValues(t, "a;b", fs.Lookup("server.tags").DefValue)
+		require.EqualValues(t, "1", fs.Lookup("level").Value.String())
+		require.EqualValues(t, "8080", fs.Lookup("admin").DefValue)
+		require.EqualValues(t, "1s", fs.Lookup("server.timeout").DefValue)
+		require.Empty(t, fs.Lookup("debug").DefValue)
+	})
+	t.Run("parse", func(t *testing.T) {
+		var cfg FlagConfig
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		require.NoError(t, RegisterFlags(fs, &cfg))
+		require.NoError(t, fs.Parse([]string{
+			"-name=svc", "-debug", "-level=3",
+			"-server.port=9000", "-server.timeout=5s", "-server.tags=x;y;z",
+		}))
+		require.EqualValues(t, "svc", cfg.Name)
+		require.True(t, cfg.Debug)
+		require.EqualValues(t, 3, *cfg.Level)
+		require.EqualValues(t, "localhost", cfg.Server.Host)
+		require.EqualValues(t, 9000, cfg.Server.Port)
+		require.EqualValues(t, 5*time.Second, cfg.Server.Timeout)
+		require.EqualValues(t, []string{"x", "y", "z"}, cfg.Server.Tags)
+		require.EqualValues(t, 8080, cfg.Backup.Port)
+		require.EqualValues(t, 9000, cfg.Admin) // follows the referenced field
+	})
+	t.Run("references", func(t *testing.T) {
+		var cfg FlagConfig
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		require.NoError(t, RegisterFlags(fs, &cfg))
+		require.NoError(t, fs.Parse([]string{"-admin=1", "-server.port=9000"}))
+		require.EqualValues(t, 1, cfg.Admin) // set by its own flag
+
+		cfg = FlagConfig{Admin: 2}
+		fs = flag.NewFlagSet("test", flag.ContinueOnError)
+		require.NoError(t, RegisterFlags(fs, &cfg))
+		require.NoError(t, fs.Parse([]string{"-server.port=9000"}))
+		require.EqualValues(t, 2, cfg.Admin) // set before
+	})
+	t.Run("provenance", func(t *testing.T) {
+		var cfg FlagConfig
+		p := Provenance{}
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		require.NoError(t, RegisterFlags(fs, &cfg, WithProvenance(p)))
+		require.NoError(t, fs.Parse([]string{"-server.port=9000"}))
+		require.EqualValues(t, "flags", p["Server.Port"])
+		require.EqualValues(t, SourceTag, p["Server.Host"])
+	})
+	t.Run("usage", func(t *testing.T) {
+		var cfg FlagConfig
+		var out bytes.Buffer
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(&out)
+		require.NoError(t, RegisterFlags(fs, &cfg))
+		fs.PrintDefaults()
+		require.Contains(t, out.String(), "-server.port value\n    \tport to listen on (default 8080)")
+		require.Contains(t, out.String(), "-debug\n    \tenable debug logs\n")
+	})
+	t.Run("invalid value", func(t *testing.T) {
+		var cfg FlagConfig
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		require.NoError(t, RegisterFlags(fs, &cfg))
+		err := fs.Parse([]string{"-server.port=abc"})
+		require.ErrorContains(t, err, `invalid value "abc" for flag -server.port: cannot set default value for Server.Port`)
+	})
+	t.Run("already defined", func(t *testing.T) {
+		var cfg struct {
+			A string `flag:"x"`
+			B string `flag:"x"`
+		}
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		require.EqualError(t, RegisterFlags(fs, &cfg), "cannot register flag for B, flag x already defined")
+
+		var other FlagConfig
+		fs = flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.String("server.port", "", "")
+		require.EqualError(t, RegisterFlags(fs, &other), "cannot register flag for Server.Port, flag server.port already defined")
+	})
+	t.Run("not pointer", func(t *testing.T) {
+		require.ErrorIs(t, RegisterFlags(flag.NewFlagSet("test", flag.ContinueOnError), FlagConfig{}), ErrNotPointer)
+	})
+}
@@ -0,0 +1,82 @@
+package go_default
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// BeforeDefaulter is implemented by a struct to run custom logic before its fields are filled
+type BeforeDefaulter interface {
+	BeforeDefaults()
+}
+
+// Defaulter is implemented by a struct to set custom defaults after its fields are filled from the tags
+//
+// SetDefaults runs before the references are resolved, so a field defaulting to another field, like
+// `$ref:Server.Port`, is still unset in it.
+type Defaulter interface {
+	SetDefaults()
+}
+
+// Validator is implemented by a struct to check its invariants after its whole subtree is filled
+//
+// The error is returned by Struct as a *ValidationError with the path of the struct.
+type Validator interface {
+	Validate() error
+}
+
+// ValidationError is the error of the Validate method of a struct
+type ValidationError struct {
+	Path string // path of the struct, empty for the top level one
+	Err  error
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("validate failed: %s", e.Err)
+	}
+	return fmt.Sprintf("validate %s failed: %s", e.Path, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// afterDefaults run the SetDefaults method of a filled struct, and queue its Validate method to run once the
+// references are resolved, value is a pointer to the struct
+//
+// The hooks of an unexported embedded struct are skipped, reflect can't call them.
+func afterDefaults(path string, value reflect.Value, cfg *Config) {
+	if hook, ok := interfaceOf(value).(Defaulter); ok {
+		hook.SetDefaults()
+	}
+	if validator, ok := interfaceOf(value).(Validator); ok {
+		cfg.validators = append(cfg.validators, validation{path: path, validator: validator})
+	}
+}
+
+// validation is a queued Validate method of the struct at path
+type validation struct {
+	path      string
+	validator Validator
+}
+
+// validate run the queued Validate methods, the nested structs first
+func (cfg *Config) validate() error {
+	validators := cfg.validators
+	cfg.validators = nil
+	for _, v := range validators {
+		if err := v.validator.Validate(); err != nil {
+			return &ValidationError{Path: v.path, Err: err}
+		}
+	}
+	return nil
+}
+
+// interfaceOf return the value as an interface, or nil if it's obtained through an unexported field
+func interfaceOf(v reflect.Value) any {
+	if !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
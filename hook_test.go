@@ -0,0 +1,91 @@
+package go_default
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type HookServer struct {
+	Host  string `default:"localhost"`
+	Port  int    `default:"8080"`
+	Addr  string
+	calls []string
+}
+
+func (s *HookServer) BeforeDefaults() {
+	s.calls = append(s.calls, fmt.Sprintf("before %s:%d", s.Host, s.Port))
+}
+
+func (s *HookServer) SetDefaults() {
+	s.calls = append(s.calls, "set")
+	if s.Addr == "" {
+		s.Addr = fmt.Sprintf("%s:%d", s.Host, s.Port)
+	}
+}
+
+func (s *HookServer) Validate() error {
+	s.calls = append(s.calls, "validate")
+	if s.Port <= 0 || s.Port > 65535 {
+		return fmt.Errorf("invalid port %d", s.Port)
+	}
+	return nil
+}
+
+type HookConfig struct {
+	Server HookServer  `default:"dive"`
+	Backup *HookServer `default:"dive"`
+	Name   string      `default:"app"`
+	calls  []string
+}
+
+func (c *HookConfig) SetDefaults() {
+	c.calls = append(c.calls, "set "+c.Server.Addr)
+}
+
+func (c HookConfig) Validate() error {
+	if c.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func TestStruct_Hooks(t *testing.T) {
+	t.Run("order", func(t *testing.T) {
+		var cfg HookConfig
+		require.NoError(t, Struct(&cfg))
+		require.EqualValues(t, []string{"before :0", "set", "validate"}, cfg.Server.calls)
+		require.EqualValues(t, "localhost:8080", cfg.Server.Addr)
+		require.EqualValues(t, "localhost:8080", cfg.Backup.Addr)
+		require.EqualValues(t, []string{"set localhost:8080"}, cfg.calls)
+	})
+	t.Run("validate nested", func(t *testing.T) {
+		cfg := HookConfig{Backup: &HookServer{Port: 70000}}
+		err := Struct(&cfg)
+		require.EqualError(t, err, "validate Backup failed: invalid port 70000")
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		require.EqualValues(t, "Backup", validationErr.Path)
+	})
+	t.Run("validate top level", func(t *testing.T) {
+		var cfg HookConfig
+		err := Struct(&cfg, WithSources(MapSource{"Name": ""}))
+		require.EqualError(t, err, "validate failed: name is required")
+	})
+}
+
+type hookBase struct {
+	Port int `default:"80"`
+}
+
+func TestStruct_HooksUnexported(t *testing.T) {
+	var cfg struct {
+		hookBase `default:"dive"`
+		Name     string `default:"app"`
+	}
+	require.NoError(t, Struct(&cfg))
+	require.EqualValues(t, 80, cfg.Port)
+	require.EqualValues(t, "app", cfg.Name)
+}
@@ -0,0 +1,161 @@
+package go_default
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// JSONPrefix marks a default value as a JSON literal, like `json:{"host":"localhost","ports":[80,443]}`
+const JSONPrefix = "json:"
+
+// JSONSetter set the default value for any type from a JSON literal prefixed by JSONPrefix
+//
+// The value is decoded by encoding/json, so struct, slice and map fields are supported.
+// Structs are only decoded when they are still zero, then the nested default tags fill what the JSON left zero.
+func JSONSetter(path string, fieldValue reflect.Value, value string) (set bool, err error) {
+	if !strings.HasPrefix(value, JSONPrefix) {
+		return false, nil
+	}
+	switch fieldValue.Type().Kind() {
+	case reflect.Slice, reflect.Map:
+		if fieldValue.Len() > 0 {
+			return true, nil // already set
+		}
+	default:
+		if !fieldValue.IsZero() {
+			return true, nil // already set
+		}
+	}
+	v := reflect.New(fieldValue.Type())
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(value, JSONPrefix)), v.Interface()); err != nil {
+		return false, fmt.Errorf("cannot set default value for %s, unmarshal %s to %s failed: %w", path, value, fieldValue.Type().String(), err)
+	}
+	fieldValue.Set(v.Elem())
+	return true, nil
+}
+
+// UnmarshalJSON decode data into v, then set the default values of the fields whose keys were absent
+//
+// Unlike Struct after json.Unmarshal, an explicit zero value like "enabled": false is kept, at any nesting level,
+// including the objects of arrays and maps of dive fields. A null value is treated like an absent key, so the defaults
+// apply, and a nil pointer to a dive struct is allocated by the allocation policy.
+func UnmarshalJSON(data []byte, v any, opts ...Option) error {
+	value := reflect.ValueOf(v)
+	if !isStructPointer(value) {
+		return ErrNotPointer
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+	present := map[string]bool{}
+	jsonPresence("", value.Type(), data, present)
+	return Struct(v, append(opts, withPresent(present))...)
+}
+
+func withPresent(present map[string]bool) Option {
+	return func(cfg *Config) {
+		cfg.present = present
+	}
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// jsonPresence record the paths of the fields present in data, matched like encoding/json does
+func jsonPresence(path string, t reflect.Type, data []byte, present map[string]bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return // decoded as a single value
+	}
+	data = bytes.TrimSpace(data)
+	switch t.Kind() {
+	case reflect.Struct:
+		var object map[string]json.RawMessage
+		if json.Unmarshal(data, &object) != nil {
+			return
+		}
+		fields := jsonFields(path, t)
+		for key, raw := range object {
+			if string(bytes.TrimSpace(raw)) == "null" {
+				continue // like an absent key, so the defaults apply, as for an Optional
+			}
+			if field, ok := matchJSONField(fields, key); ok {
+				present[field.path] = true
+				jsonPresence(field.path, field.typ, raw, present)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		var array []json.RawMessage
+		if json.Unmarshal(data, &array) != nil {
+			return
+		}
+		for i, raw := range array {
+			jsonPresence(fmt.Sprintf("%s[%d]", path, i), t.Elem(), raw, present)
+		}
+	case reflect.Map:
+		var object map[string]json.RawMessage
+		if json.Unmarshal(data, &object) != nil {
+			return
+		}
+		for key, raw := range object {
+			jsonPresence(fmt.Sprintf("%s[%s]", path, key), t.Elem(), raw, present)
+		}
+	}
+}
+
+type jsonField struct {
+	name string
+	path string
+	typ  reflect.Type
+}
+
+// jsonFields list the fields of a struct by their JSON names, with the fields of untagged embedded structs
+func jsonFields(deepName string, t reflect.Type) []jsonField {
+	var fields []jsonField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			fields = append(fields, jsonFields(path(deepName, field.Name), fieldType)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, jsonField{name: name, path: path(deepName, field.Name), typ: field.Type})
+	}
+	return fields
+}
+
+// matchJSONField find the field of a key, preferring an exact match over a case-insensitive one
+func matchJSONField(fields []jsonField, key string) (jsonField, bool) {
+	for _, field := range fields {
+		if field.name == key {
+			return field, true
+		}
+	}
+	for _, field := range fields {
+		if strings.EqualFold(field.name, key) {
+			return field, true
+		}
+	}
+	return jsonField{}, false
+}
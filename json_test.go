@@ -0,0 +1,162 @@
+package go_default
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type JSONServer struct {
+	Host    string `json:"host" default:"localhost"`
+	Ports   []int  `json:"ports"`
+	Timeout int    `json:"timeout" default:"30"`
+}
+
+func TestStruct_JSON(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		var foo struct {
+			Server    JSONServer        `default:"json:{\"host\":\"a\",\"ports\":[1,2]}"`
+			ServerPtr *JSONServer       `default:"json:{\"ports\":[3]}"`
+			Slice     []string          `default:"json:[\"x\",\"y\"]"`
+			Map       map[string]int    `default:"json:{\"a\":1,\"b\":2}"`
+			Servers   []JSONServer      `default:"json:[{\"host\":\"b\"}]"`
+			Any       any               `default:"json:{\"k\":true}"`
+			IntPtr    *int              `default:"json:5"`
+			Labels    map[string]string `default:"json:null"`
+		}
+		err := Struct(&foo)
+		require.NoError(t, err)
+		require.EqualValues(t, JSONServer{Host: "a", Ports: []int{1, 2}, Timeout: 30}, foo.Server)
+		require.EqualValues(t, JSONServer{Host: "localhost", Ports: []int{3}, Timeout: 30}, *foo.ServerPtr)
+		require.EqualValues(t, []string{"x", "y"}, foo.Slice)
+		require.EqualValues(t, map[string]int{"a": 1, "b": 2}, foo.Map)
+		require.EqualValues(t, []JSONServer{{Host: "b"}}, foo.Servers)
+		require.EqualValues(t, map[string]any{"k": true}, foo.Any)
+		require.EqualValues(t, 5, *foo.IntPtr)
+		require.Nil(t, foo.Labels)
+	})
+	t.Run("not set", func(t *testing.T) {
+		var foo struct {
+			Server    JSONServer     `default:"json:{\"host\":\"a\",\"ports\":[1,2]}"`
+			ServerPtr *JSONServer    `default:"json:{\"host\":\"b\"}"`
+			Map       map[string]int `default:"json:{\"a\":1}"`
+		}
+		foo.Server.Ports = []int{8080}
+		foo.ServerPtr = &JSONServer{Timeout: 5}
+		foo.Map = map[string]int{"z": 26}
+		err := Struct(&foo)
+		require.NoError(t, err)
+		require.EqualValues(t, JSONServer{Host: "localhost", Ports: []int{8080}, Timeout: 30}, foo.Server)
+		require.EqualValues(t, JSONServer{Host: "localhost", Timeout: 5}, *foo.ServerPtr)
+		require.EqualValues(t, map[string]int{"z": 26}, foo.Map)
+	})
+	t.Run("should return error when failed to unmarshal json", func(t *testing.T) {
+		var foo struct {
+			Slice []int `default:"json:[1,"`
+		}
+		err := Struct(&foo)
+		require.ErrorContains(t, err, "cannot set default value for Slice, unmarshal json:[1, to []int failed")
+	})
+}
+
+type PresenceFeature struct {
+	Name    string `json:"name" default:"feature"`
+	Enabled bool   `json:"enabled" default:"true"`
+	Weight  int    `json:"weight" default:"10"`
+}
+
+type PresenceBase struct {
+	Region string `default:"us"`
+}
+
+type PresenceConfig struct {
+	PresenceBase `default:"dive"`
+	Enabled      bool                        `json:"enabled" default:"true"`
+	Retries      int                         `default:"3"`
+	Name         *string                     `default:"app"`
+	Feature      PresenceFeature             `json:"feature" default:"dive"`
+	FeaturePtr   *PresenceFeature            `json:"featurePtr" default:"dive"`
+	Features     []PresenceFeature           `json:"features" default:"dive"`
+	FeatureMap   map[string]*PresenceFeature `json:"featureMap" default:"dive"`
+	Ignored      string                      `json:"-" default:"ignored"`
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	var cfg PresenceConfig
+	err := UnmarshalJSON([]byte(`{
+		"enabled": false,
+		"RETRIES": 0,
+		"Name": null,
+		"Region": "",
+		"feature": {"enabled": false},
+		"featurePtr": {"weight": 0},
+		"features": [{"enabled": false}, {"name": "", "weight": 1}],
+		"featureMap": {"a": {"enabled": false}, "b": {}}
+	}`), &cfg)
+	require.NoError(t, err)
+	require.False(t, cfg.Enabled)
+	require.Zero(t, cfg.Retries)
+	require.EqualValues(t, "app", *cfg.Name) // null is treated like an absent key
+	require.Empty(t, cfg.Region)
+	require.EqualValues(t, "ignored", cfg.Ignored)
+	require.EqualValues(t, PresenceFeature{Name: "feature", Enabled: false, Weight: 10}, cfg.Feature)
+	require.EqualValues(t, PresenceFeature{Name: "feature", Enabled: true, Weight: 0}, *cfg.FeaturePtr)
+	require.EqualValues(t, []PresenceFeature{
+		{Name: "feature", Enabled: false, Weight: 10},
+		{Name: "", Enabled: true, Weight: 1},
+	}, cfg.Features)
+	require.EqualValues(t, map[string]*PresenceFeature{
+		"a": {Name: "feature", Enabled: false, Weight: 10},
+		"b": {Name: "feature", Enabled: true, Weight: 10},
+	}, cfg.FeatureMap)
+
+	t.Run("absent", func(t *testing.T) {
+		var cfg PresenceConfig
+		require.NoError(t, UnmarshalJSON([]byte(`{}`), &cfg))
+		require.True(t, cfg.Enabled)
+		require.EqualValues(t, 3, cfg.Retries)
+		require.EqualValues(t, "app", *cfg.Name)
+		require.EqualValues(t, "us", cfg.Region)
+		require.EqualValues(t, PresenceFeature{Name: "feature", Enabled: true, Weight: 10}, cfg.Feature)
+		require.Empty(t, cfg.Features)
+	})
+	t.Run("null", func(t *testing.T) {
+		var cfg struct {
+			Enabled Optional[bool]   `json:"enabled" default:"true"`
+			Backup  *PresenceFeature `json:"backup" default:"dive"`
+			Feature PresenceFeature  `json:"feature" default:"dive"`
+		}
+		require.NoError(t, UnmarshalJSON([]byte(`{"enabled": null, "backup": null, "feature": {"weight": null}}`), &cfg))
+		require.EqualValues(t, Some(true), cfg.Enabled)
+		require.EqualValues(t, PresenceFeature{Name: "feature", Enabled: true, Weight: 10}, *cfg.Backup)
+		require.EqualValues(t, 10, cfg.Feature.Weight)
+
+		// a nil pointer is kept by the allocation policy
+		cfg.Backup = nil
+		require.NoError(t, UnmarshalJSON([]byte(`{"backup": null}`), &cfg, WithAllocPolicy(AllocNever)))
+		require.Nil(t, cfg.Backup)
+	})
+	t.Run("invalid JSON", func(t *testing.T) {
+		var cfg PresenceConfig
+		require.Error(t, UnmarshalJSON([]byte(`{`), &cfg))
+	})
+	t.Run("not pointer", func(t *testing.T) {
+		require.ErrorIs(t, UnmarshalJSON([]byte(`{}`), PresenceConfig{}), ErrNotPointer)
+	})
+}
+
+func TestStruct_DiveElems(t *testing.T) {
+	cfg := PresenceConfig{
+		Features:   []PresenceFeature{{Name: "a"}, {}},
+		FeatureMap: map[string]*PresenceFeature{"x": nil, "y": {Weight: 1}},
+	}
+	require.NoError(t, Struct(&cfg))
+	require.EqualValues(t, []PresenceFeature{
+		{Name: "a", Enabled: true, Weight: 10},
+		{Name: "feature", Enabled: true, Weight: 10},
+	}, cfg.Features)
+	require.EqualValues(t, map[string]*PresenceFeature{
+		"x": {Name: "feature", Enabled: true, Weight: 10},
+		"y": {Name: "feature", Enabled: true, Weight: 1},
+	}, cfg.FeatureMap)
+}
@@ -0,0 +1,182 @@
+package go_default
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MergeStrategy decide how Merge combines a non-empty slice or map with the default one
+type MergeStrategy int
+
+const (
+	// MergeReplace keep a non-empty slice or map as is, the default one is only used if it's empty
+	MergeReplace MergeStrategy = iota
+	// MergeAppend append the default elements after the elements of a slice, and add the missing keys to a map
+	MergeAppend
+	// MergeKeys merge the elements of a slice by index and the values of a map by key, then add the missing ones
+	MergeKeys
+)
+
+// WithSliceMerge set the strategy of Merge for non-empty slices, MergeReplace by default
+func WithSliceMerge(strategy MergeStrategy) Option {
+	return func(cfg *Config) {
+		cfg.sliceMerge = strategy
+	}
+}
+
+// WithMapMerge set the strategy of Merge for non-empty maps, MergeReplace by default
+func WithMapMerge(strategy MergeStrategy) Option {
+	return func(cfg *Config) {
+		cfg.mapMerge = strategy
+	}
+}
+
+// Merge copy the values of defaults into the zero fields of dst, then fill what is still zero from the tags
+//
+// It walks both values with the same zero detection as Struct, recursing into nested structs, pointers, arrays,
+// slices and maps, so defaults written in Go, like a DefaultConfig() function, compose with the tag values.
+// The pointers, slices and maps of defaults are copied, so dst never shares them, and a struct without exported fields,
+// like big.Int, is copied by its Set method if any. Unexported fields are left as is.
+func Merge[T any](dst *T, defaults T, opts ...Option) error {
+	cfg := newConfig(opts...)
+	if len(cfg.errs) > 0 {
+		return cfg.errs[0]
+	}
+	v := reflect.ValueOf(dst)
+	if !isStructPointer(v) || v.IsNil() {
+		return ErrNotPointer
+	}
+	mergeValue("", v.Elem(), reflect.ValueOf(defaults), cfg)
+	return Struct(dst, opts...)
+}
+
+// mergeValue merge src into dst at deepName, dst must be settable
+func mergeValue(deepName string, dst, src reflect.Value, cfg *Config) {
+	switch dst.Kind() {
+	case reflect.Struct:
+		if opt, ok := interfaceOf(dst.Addr()).(optionalValue); ok {
+			// an Optional is a single value, kept even if its value is zero
+			if opt.isSet() {
+				return
+			}
+			dst.Set(reflect.Zero(dst.Type()))
+		}
+		if !hasExportedFields(dst.Type()) {
+			// e.g. time.Time, treated as a single value
+			if cfg.mergeUnset(deepName, dst) {
+				setOpaque(dst, src)
+			}
+			return
+		}
+		for i := 0; i < dst.NumField(); i++ {
+			if field := dst.Type().Field(i); field.IsExported() {
+				mergeValue(path(deepName, field.Name), dst.Field(i), src.Field(i), cfg)
+			}
+		}
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		mergeValue(deepName, dst.Elem(), src.Elem(), cfg)
+	case reflect.Array:
+		for i := 0; i < dst.Len(); i++ {
+			mergeValue(fmt.Sprintf("%s[%d]", deepName, i), dst.Index(i), src.Index(i), cfg)
+		}
+	case reflect.Slice:
+		mergeSlice(deepName, dst, src, cfg)
+	case reflect.Map:
+		mergeMap(deepName, dst, src, cfg)
+	default:
+		if cfg.mergeUnset(deepName, dst) {
+			dst.Set(src)
+		}
+	}
+}
+
+// mergeUnset report whether a value is unset by the zero policy and the IsZero method, like for Struct, or else
+// whether it's zero, so a set interface or struct is kept
+func (cfg *Config) mergeUnset(path string, v reflect.Value) bool {
+	if _, ok := asZeroer(v); !ok && !cfg.overwrite && cfg.zeroPolicy == nil {
+		return v.IsZero()
+	}
+	unset, _ := cfg.unset(path, v)
+	return unset
+}
+
+// setOpaque set a struct without exported fields, by its Set method if any, like big.Int, so no pointer is shared
+func setOpaque(dst, src reflect.Value) {
+	set := dst.Addr().MethodByName("Set")
+	if set.IsValid() && set.Type().NumIn() == 1 && set.Type().In(0) == dst.Addr().Type() {
+		value := reflect.New(src.Type())
+		value.Elem().Set(src)
+		set.Call([]reflect.Value{value})
+		return
+	}
+	dst.Set(src)
+}
+
+func mergeSlice(path string, dst, src reflect.Value, cfg *Config) {
+	if src.Len() == 0 {
+		return
+	}
+	n := dst.Len()
+	switch {
+	case n == 0:
+		dst.Set(reflect.MakeSlice(dst.Type(), 0, src.Len()))
+	case cfg.sliceMerge == MergeAppend:
+		n = 0
+	case cfg.sliceMerge == MergeKeys:
+		for i := 0; i < n && i < src.Len(); i++ {
+			mergeValue(fmt.Sprintf("%s[%d]", path, i), dst.Index(i), src.Index(i), cfg)
+		}
+	default:
+		return
+	}
+	for i := n; i < src.Len(); i++ {
+		dst.Set(reflect.Append(dst, copyValue(src.Index(i))))
+	}
+}
+
+func mergeMap(path string, dst, src reflect.Value, cfg *Config) {
+	if src.Len() == 0 || dst.Len() > 0 && cfg.mapMerge == MergeReplace {
+		return
+	}
+	if dst.IsNil() {
+		dst.Set(reflect.MakeMapWithSize(dst.Type(), src.Len()))
+	}
+	iter := src.MapRange()
+	for iter.Next() {
+		current := dst.MapIndex(iter.Key())
+		if !current.IsValid() {
+			dst.SetMapIndex(iter.Key(), copyValue(iter.Value()))
+			continue
+		}
+		if cfg.mapMerge == MergeKeys {
+			// map values are not addressable, so merge a copy and put it back
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			elem.Set(current)
+			mergeValue(fmt.Sprintf("%s[%v]", path, iter.Key()), elem, iter.Value(), cfg)
+			dst.SetMapIndex(iter.Key(), elem)
+		}
+	}
+}
+
+// copyValue return a copy of v that shares no pointer, slice or map with it
+func copyValue(v reflect.Value) reflect.Value {
+	c := reflect.New(v.Type()).Elem()
+	// a new config, so every value is copied whatever the zero policy
+	mergeValue("", c, v, &Config{})
+	return c
+}
+
+func hasExportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
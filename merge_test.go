@@ -0,0 +1,157 @@
+package go_default
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type MergeServer struct {
+	Host    string `default:"localhost"`
+	Port    int    `default:"8080"`
+	Timeout time.Duration
+}
+
+type MergeConfig struct {
+	Name     string
+	Debug    bool
+	Started  time.Time
+	Server   MergeServer  `default:"dive"`
+	Backup   *MergeServer `default:"dive"`
+	Level    *int
+	Ports    [2]int
+	Tags     []string
+	Servers  []MergeServer
+	Labels   map[string]string
+	Limits   map[string]MergeServer
+	internal string
+}
+
+func DefaultMergeConfig() MergeConfig {
+	level := 3
+	return MergeConfig{
+		Name:     "app",
+		Debug:    true,
+		Started:  time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC),
+		Server:   MergeServer{Port: 9000, Timeout: time.Second},
+		Backup:   &MergeServer{Host: "backup.local"},
+		Level:    &level,
+		Ports:    [2]int{80, 443},
+		Tags:     []string{"a", "b"},
+		Servers:  []MergeServer{{Host: "s1", Timeout: time.Second}, {Host: "s2"}},
+		Labels:   map[string]string{"env": "prod", "team": "core"},
+		Limits:   map[string]MergeServer{"api": {Timeout: time.Second}, "web": {Port: 80}},
+		internal: "x",
+	}
+}
+
+func TestMerge(t *testing.T) {
+	t.Run("fill zero", func(t *testing.T) {
+		var cfg MergeConfig
+		defaults := DefaultMergeConfig()
+		require.NoError(t, Merge(&cfg, defaults))
+		require.EqualValues(t, "app", cfg.Name)
+		require.True(t, cfg.Debug)
+		require.EqualValues(t, defaults.Started, cfg.Started)
+		require.EqualValues(t, MergeServer{Host: "localhost", Port: 9000, Timeout: time.Second}, cfg.Server)
+		require.EqualValues(t, MergeServer{Host: "backup.local", Port: 8080}, *cfg.Backup)
+		require.EqualValues(t, 3, *cfg.Level)
+		require.EqualValues(t, [2]int{80, 443}, cfg.Ports)
+		require.EqualValues(t, []string{"a", "b"}, cfg.Tags)
+		require.EqualValues(t, defaults.Servers, cfg.Servers)
+		require.EqualValues(t, defaults.Labels, cfg.Labels)
+		require.EqualValues(t, defaults.Limits, cfg.Limits)
+		require.Empty(t, cfg.internal)
+
+		// nothing is shared with the defaults
+		require.NotSame(t, defaults.Backup, cfg.Backup)
+		require.NotSame(t, defaults.Level, cfg.Level)
+		cfg.Tags[0] = "changed"
+		cfg.Labels["env"] = "changed"
+		require.EqualValues(t, "a", defaults.Tags[0])
+		require.EqualValues(t, "prod", defaults.Labels["env"])
+	})
+	t.Run("keep set", func(t *testing.T) {
+		level := 1
+		cfg := MergeConfig{
+			Name:    "svc",
+			Server:  MergeServer{Host: "svc.local"},
+			Backup:  &MergeServer{Port: 7000},
+			Level:   &level,
+			Ports:   [2]int{8080},
+			Tags:    []string{"x"},
+			Servers: []MergeServer{{Port: 1}},
+			Labels:  map[string]string{"env": "dev"},
+			Limits:  map[string]MergeServer{"api": {Port: 1}},
+		}
+		require.NoError(t, Merge(&cfg, DefaultMergeConfig()))
+		require.EqualValues(t, "svc", cfg.Name)
+		require.EqualValues(t, MergeServer{Host: "svc.local", Port: 9000, Timeout: time.Second}, cfg.Server)
+		require.EqualValues(t, MergeServer{Host: "backup.local", Port: 7000}, *cfg.Backup)
+		require.EqualValues(t, 1, *cfg.Level)
+		require.EqualValues(t, [2]int{8080, 443}, cfg.Ports)
+		require.EqualValues(t, []string{"x"}, cfg.Tags)
+		require.EqualValues(t, []MergeServer{{Port: 1}}, cfg.Servers)
+		require.EqualValues(t, map[string]string{"env": "dev"}, cfg.Labels)
+		require.EqualValues(t, map[string]MergeServer{"api": {Port: 1}}, cfg.Limits)
+	})
+	t.Run("append", func(t *testing.T) {
+		cfg := MergeConfig{
+			Tags:   []string{"x"},
+			Labels: map[string]string{"env": "dev"},
+			Limits: map[string]MergeServer{"api": {Port: 1}},
+		}
+		require.NoError(t, Merge(&cfg, DefaultMergeConfig(), WithSliceMerge(MergeAppend), WithMapMerge(MergeAppend)))
+		require.EqualValues(t, []string{"x", "a", "b"}, cfg.Tags)
+		require.EqualValues(t, map[string]string{"env": "dev", "team": "core"}, cfg.Labels)
+		require.EqualValues(t, map[string]MergeServer{"api": {Port: 1}, "web": {Port: 80}}, cfg.Limits)
+	})
+	t.Run("merge keys", func(t *testing.T) {
+		cfg := MergeConfig{
+			Tags:    []string{"x"},
+			Servers: []MergeServer{{Port: 1}},
+			Limits:  map[string]MergeServer{"api": {Port: 1}},
+		}
+		require.NoError(t, Merge(&cfg, DefaultMergeConfig(), WithSliceMerge(MergeKeys), WithMapMerge(MergeKeys)))
+		require.EqualValues(t, []string{"x", "b"}, cfg.Tags)
+		require.EqualValues(t, []MergeServer{{Host: "s1", Port: 1, Timeout: time.Second}, {Host: "s2"}}, cfg.Servers)
+		require.EqualValues(t, map[string]MergeServer{"api": {Port: 1, Timeout: time.Second}, "web": {Port: 80}}, cfg.Limits)
+	})
+	t.Run("set method", func(t *testing.T) {
+		type Limits struct {
+			Max big.Int
+		}
+		var cfg, defaults Limits
+		defaults.Max.SetInt64(100)
+		require.NoError(t, Merge(&cfg, defaults))
+		require.EqualValues(t, 0, cfg.Max.Cmp(big.NewInt(100)))
+
+		// the words of the defaults are not shared
+		defaults.Max.SetInt64(1)
+		require.EqualValues(t, 0, cfg.Max.Cmp(big.NewInt(100)))
+	})
+	t.Run("zero detection", func(t *testing.T) {
+		type Listen struct {
+			Name string
+			Port Port
+		}
+		cfg := Listen{Name: "TODO", Port: -1}
+		policy := func(path string, v reflect.Value) bool {
+			return v.Kind() == reflect.String && v.String() == "TODO" || v.IsZero()
+		}
+		require.NoError(t, Merge(&cfg, Listen{Name: "api", Port: 80}, WithZeroPolicy(policy)))
+		require.EqualValues(t, Listen{Name: "api", Port: -1}, cfg) // the policy replaces the IsZero method
+
+		cfg = Listen{Name: "TODO", Port: -1}
+		require.NoError(t, Merge(&cfg, Listen{Name: "api", Port: 80}))
+		require.EqualValues(t, Listen{Name: "TODO", Port: 80}, cfg)
+	})
+	t.Run("not struct", func(t *testing.T) {
+		var i int
+		require.ErrorIs(t, Merge(&i, 1), ErrNotPointer)
+		require.ErrorIs(t, Merge[MergeConfig](nil, MergeConfig{}), ErrNotPointer)
+	})
+}
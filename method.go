@@ -0,0 +1,65 @@
+package go_default
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MethodPrefix marks a default value as computed by a method of the parent struct, like `method:ComputeTimeout`
+//
+// Without a tag, a method named "Default" followed by the field name is used if any, like DefaultTimeout for Timeout.
+// The method takes no argument and returns a value assignable to the field, or a string converted like a tag value,
+// and an optional error. It's only called if the field is zero.
+const MethodPrefix = "method:"
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// defaultMethodName return the name of the method that computes the default value of a field, if any
+func defaultMethodName(parent reflect.Value, field reflect.StructField, tag Tag, tagValue string) (string, bool) {
+	if !tag.Quoted && strings.HasPrefix(tag.Value, MethodPrefix) {
+		return strings.TrimPrefix(tag.Value, MethodPrefix), true
+	}
+	if tagValue != "" {
+		return "", false
+	}
+	name := "Default" + field.Name
+	return name, parent.CanInterface() && parent.MethodByName(name).IsValid()
+}
+
+// callDefaultMethod set the value returned by a method of the parent struct to a zero field
+func callDefaultMethod(path string, parent reflect.Value, name string, fieldValue reflect.Value, tag Tag, cfg *Config) error {
+	unset, reset := cfg.unset(path, fieldValue)
+	if !unset || !reset && !fieldValue.IsZero() {
+		return nil
+	}
+	if reset {
+		fieldValue.Set(reflect.Zero(fieldValue.Type()))
+	}
+	method := parent.MethodByName(name)
+	if !method.IsValid() || !method.CanInterface() {
+		// the methods of an unexported embedded struct can't be called
+		return fmt.Errorf("cannot set default value for %s, unknown method %s of %s", path, name, parent.Type().Elem().String())
+	}
+	t := method.Type()
+	if t.NumIn() != 0 || t.NumOut() == 0 || t.NumOut() > 2 || t.NumOut() == 2 && t.Out(1) != errorType {
+		return fmt.Errorf("cannot set default value for %s, method %s must take no argument and return a value and an optional error", path, name)
+	}
+
+	out := method.Call(nil)
+	if len(out) == 2 && !out[1].IsNil() {
+		return fmt.Errorf("cannot set default value for %s, method %s failed: %w", path, name, out[1].Interface().(error))
+	}
+	switch result := out[0]; {
+	case result.Type().AssignableTo(fieldValue.Type()):
+		fieldValue.Set(result)
+	case result.Kind() == reflect.String:
+		if err := fillSome(path, fieldValue, Tag{Value: result.String(), Quoted: true, Options: tag.Options}, cfg); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("cannot set default value for %s, method %s returns %s, not assignable to %s", path, name, result.Type().String(), fieldValue.Type().String())
+	}
+	cfg.record(path, "method "+name)
+	return nil
+}
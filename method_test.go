@@ -0,0 +1,116 @@
+package go_default
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type MethodServer struct {
+	Workers int
+	Timeout time.Duration `default:"method:ComputeTimeout"`
+	Port    int           `default:"8080"`
+	Addr    string
+	Tags    []string `default:"method:ComputeTags,sep=';'"`
+}
+
+func (s MethodServer) DefaultWorkers() int {
+	return runtime.NumCPU() * 2
+}
+
+func (s *MethodServer) ComputeTimeout() (time.Duration, error) {
+	return time.Duration(s.Workers) * time.Second, nil
+}
+
+func (s *MethodServer) DefaultAddr() string {
+	return fmt.Sprintf("localhost:%d", s.Port)
+}
+
+func (s MethodServer) ComputeTags() string {
+	return "a;b"
+}
+
+type MethodConfig struct {
+	Server  MethodServer  `default:"dive"`
+	Backup  *MethodServer `default:"dive"`
+	Started time.Time
+	Name    string `default:"app"`
+}
+
+func (c MethodConfig) DefaultStarted() time.Time {
+	return time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+}
+
+func (c MethodConfig) DefaultName() string {
+	return "ignored, the tag wins"
+}
+
+type MethodErrorConfig struct {
+	Unknown  int `default:"method:Nope"`
+	Failed   int `default:"method:Fail"`
+	WrongIn  int `default:"method:TakeArg"`
+	WrongOut int `default:"method:ReturnBool"`
+}
+
+func (c MethodErrorConfig) Fail() (int, error) {
+	return 0, errors.New("boom")
+}
+
+func (c MethodErrorConfig) TakeArg(int) int {
+	return 1
+}
+
+func (c MethodErrorConfig) ReturnBool() bool {
+	return true
+}
+
+func TestStruct_Method(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		var cfg MethodConfig
+		p := Provenance{}
+		require.NoError(t, Struct(&cfg, WithProvenance(p)))
+		workers := runtime.NumCPU() * 2
+		require.EqualValues(t, workers, cfg.Server.Workers)
+		require.EqualValues(t, time.Duration(workers)*time.Second, cfg.Server.Timeout)
+		require.EqualValues(t, "localhost:8080", cfg.Server.Addr)
+		require.EqualValues(t, []string{"a", "b"}, cfg.Server.Tags)
+		require.EqualValues(t, workers, cfg.Backup.Workers)
+		require.EqualValues(t, time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC), cfg.Started)
+		require.EqualValues(t, "app", cfg.Name)
+		require.EqualValues(t, "method ComputeTimeout", p["Server.Timeout"])
+		require.EqualValues(t, "method DefaultWorkers", p["Server.Workers"])
+	})
+	t.Run("keep set", func(t *testing.T) {
+		started := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		cfg := MethodConfig{Server: MethodServer{Workers: 1, Timeout: time.Minute}, Started: started}
+		require.NoError(t, Struct(&cfg))
+		require.EqualValues(t, 1, cfg.Server.Workers)
+		require.EqualValues(t, time.Minute, cfg.Server.Timeout)
+		require.EqualValues(t, started, cfg.Started)
+	})
+	t.Run("errors", func(t *testing.T) {
+		for field, msg := range map[string]string{
+			"Unknown":  "cannot set default value for Unknown, unknown method Nope of go_default.MethodErrorConfig",
+			"Failed":   "cannot set default value for Failed, method Fail failed: boom",
+			"WrongIn":  "cannot set default value for WrongIn, method TakeArg must take no argument and return a value and an optional error",
+			"WrongOut": "cannot set default value for WrongOut, method ReturnBool returns bool, not assignable to int",
+		} {
+			cfg := MethodErrorConfig{Unknown: 1, Failed: 1, WrongIn: 1, WrongOut: 1}
+			switch field {
+			case "Unknown":
+				cfg.Unknown = 0
+			case "Failed":
+				cfg.Failed = 0
+			case "WrongIn":
+				cfg.WrongIn = 0
+			case "WrongOut":
+				cfg.WrongOut = 0
+			}
+			require.EqualError(t, Struct(&cfg), msg, field)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package go_default
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// Optional is a value that tracks whether it was set, so an explicit zero value is kept by Struct
+//
+// The default value only applies if Set is false, it's converted by the setters for T, then Set becomes true.
+// In JSON, null is an unset value.
+type Optional[T any] struct {
+	Value T
+	Set   bool
+}
+
+// Some return a set Optional
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{Value: value, Set: true}
+}
+
+// Get return the value, and whether it was set
+func (o Optional[T]) Get() (T, bool) {
+	return o.Value, o.Set
+}
+
+// OrElse return the value if it was set, or fallback
+func (o Optional[T]) OrElse(fallback T) T {
+	if o.Set {
+		return o.Value
+	}
+	return fallback
+}
+
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Set {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	var zero T
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		o.Value, o.Set = zero, false
+		return nil
+	}
+	if err := json.Unmarshal(data, &o.Value); err != nil {
+		return err
+	}
+	o.Set = true
+	return nil
+}
+
+func (o Optional[T]) MarshalText() ([]byte, error) {
+	if !o.Set {
+		return nil, nil
+	}
+	return []byte(formatValue(reflect.ValueOf(&o.Value).Elem())), nil
+}
+
+// UnmarshalText convert text by the default setters for T
+func (o *Optional[T]) UnmarshalText(text []byte) error {
+	var value T
+	if err := fillSome("", reflect.ValueOf(&value).Elem(), Tag{Value: string(text), Quoted: true}, newConfig()); err != nil {
+		return err
+	}
+	o.Value, o.Set = value, true
+	return nil
+}
+
+func (o *Optional[T]) isSet() bool {
+	return o.Set
+}
+
+func (o *Optional[T]) value() reflect.Value {
+	return reflect.ValueOf(&o.Value).Elem()
+}
+
+func (o *Optional[T]) markSet() {
+	o.Set = true
+}
+
+// optionalValue is implemented by *Optional[T] for any T
+type optionalValue interface {
+	isSet() bool
+	value() reflect.Value
+	markSet()
+}
+
+// fillOptional fill the value of an unset Optional by the setters for its type
+func fillOptional(path string, opt optionalValue, tag Tag, cfg *Config) error {
+	if opt.isSet() {
+		return nil
+	}
+	if err := fillSome(path, opt.value(), tag, cfg); err != nil {
+		return err
+	}
+	opt.markSet()
+	return nil
+}
@@ -0,0 +1,91 @@
+package go_default
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type OptionalConfig struct {
+	Enabled Optional[bool]          `json:"enabled" default:"true"`
+	Retries Optional[int]           `json:"retries" default:"3"`
+	Timeout Optional[time.Duration] `json:"timeout" default:"1s"`
+	Level   Optional[Level]         `json:"level" default:"Warn"`
+	Tags    Optional[[]string]      `json:"tags" default:"a,b,sep=','"`
+	Name    Optional[string]        `json:"name"`
+}
+
+func TestOptional(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		var cfg OptionalConfig
+		require.NoError(t, Struct(&cfg))
+		require.EqualValues(t, Some(true), cfg.Enabled)
+		require.EqualValues(t, Some(3), cfg.Retries)
+		require.EqualValues(t, Some(time.Second), cfg.Timeout)
+		require.EqualValues(t, Some(LevelWarn), cfg.Level)
+		require.EqualValues(t, Some([]string{"a", "b"}), cfg.Tags)
+		require.False(t, cfg.Name.Set)
+	})
+	t.Run("explicit zero", func(t *testing.T) {
+		cfg := OptionalConfig{Enabled: Some(false), Retries: Some(0)}
+		require.NoError(t, Struct(&cfg))
+		require.EqualValues(t, Some(false), cfg.Enabled)
+		require.EqualValues(t, Some(0), cfg.Retries)
+	})
+	t.Run("merge", func(t *testing.T) {
+		cfg := OptionalConfig{Enabled: Some(false)}
+		require.NoError(t, Merge(&cfg, OptionalConfig{Enabled: Some(true), Retries: Some(5), Tags: Some([]string{"x"})}))
+		require.EqualValues(t, Some(false), cfg.Enabled)
+		require.EqualValues(t, Some(5), cfg.Retries)
+		require.EqualValues(t, Some([]string{"x"}), cfg.Tags)
+		require.EqualValues(t, Some(time.Second), cfg.Timeout)
+	})
+	t.Run("json", func(t *testing.T) {
+		var cfg OptionalConfig
+		require.NoError(t, json.Unmarshal([]byte(`{"enabled": false, "retries": null, "timeout": 5000000000}`), &cfg))
+		require.NoError(t, Struct(&cfg))
+		require.EqualValues(t, Some(false), cfg.Enabled)
+		require.EqualValues(t, Some(3), cfg.Retries)
+		require.EqualValues(t, Some(5*time.Second), cfg.Timeout)
+
+		b, err := json.Marshal(OptionalConfig{Enabled: Some(false), Timeout: Some(time.Second)})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"enabled": false, "retries": null, "timeout": 1000000000, "level": null, "tags": null, "name": null}`, string(b))
+	})
+	t.Run("text", func(t *testing.T) {
+		var timeout Optional[time.Duration]
+		require.NoError(t, timeout.UnmarshalText([]byte("1m")))
+		require.EqualValues(t, Some(time.Minute), timeout)
+		text, err := timeout.MarshalText()
+		require.NoError(t, err)
+		require.EqualValues(t, "1m0s", string(text))
+
+		var level Optional[Level]
+		require.Error(t, level.UnmarshalText([]byte("Nope")))
+		require.False(t, level.Set)
+		text, err = level.MarshalText()
+		require.NoError(t, err)
+		require.Empty(t, text)
+	})
+	t.Run("accessors", func(t *testing.T) {
+		var unset Optional[int]
+		value, ok := unset.Get()
+		require.Zero(t, value)
+		require.False(t, ok)
+		require.EqualValues(t, 7, unset.OrElse(7))
+
+		value, ok = Some(0).Get()
+		require.Zero(t, value)
+		require.True(t, ok)
+		require.EqualValues(t, 0, Some(0).OrElse(7))
+	})
+	t.Run("invalid default", func(t *testing.T) {
+		var cfg struct {
+			Port Optional[int] `default:"abc"`
+		}
+		require.ErrorContains(t, Struct(&cfg), "cannot set default value for Port, parse abc to int failed")
+		require.False(t, cfg.Port.Set)
+	})
+}
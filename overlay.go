@@ -0,0 +1,140 @@
+package go_default
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// WithDefaultsFile replace the tag values by the values of a JSON file keyed by field path, see WithDefaultsJSON
+//
+// The file is read whenever Struct applies the option.
+func WithDefaultsFile(name string) Option {
+	return func(cfg *Config) {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			err = fmt.Errorf("read defaults file %s failed: %w", name, err)
+		}
+		withDefaults(name, b, err)(cfg)
+	}
+}
+
+// WithDefaultsJSON replace the tag values by the values of a JSON object keyed by field path
+//
+// The keys can be dotted paths like {"Server.Port": "8080"}, nested objects that mirror the struct like
+// {"Server": {"Port": 8080}}, or both. Arrays and objects at a non-dive field are decoded as JSON literals.
+// The values are converted like tag values and only fill zero fields. Unknown paths return an error. Later overlays
+// take precedence over earlier ones, like the sources.
+func WithDefaultsJSON(r io.Reader) Option {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		err = fmt.Errorf("read defaults JSON failed: %w", err)
+	}
+	return withDefaults("JSON", b, err)
+}
+
+func withDefaults(name string, b []byte, err error) Option {
+	values, objects := map[string]string{}, map[string]bool{}
+	if err == nil {
+		if err = flattenJSON("", b, values, objects); err != nil {
+			err = fmt.Errorf("parse defaults %s failed: %w", name, err)
+		}
+	}
+	return func(cfg *Config) {
+		if err != nil {
+			cfg.errs = append(cfg.errs, err)
+			return
+		}
+		cfg.defaults = append(cfg.defaults, &defaultsOverlay{name: name, values: values, objects: objects, seen: map[string]bool{}})
+	}
+}
+
+// defaultsOverlay replace the tag values by path, and track the visited paths to report the unknown ones
+type defaultsOverlay struct {
+	name    string
+	values  map[string]string // values keyed by path, objects are also kept as JSON literals
+	objects map[string]bool   // paths of the objects, checked by their leaves
+	seen    map[string]bool   // paths that consumed a value or were skipped, with their whole subtree
+}
+
+func (o *defaultsOverlay) String() string {
+	return "defaults " + o.name
+}
+
+func (o *defaultsOverlay) lookup(path string) (string, bool) {
+	value, ok := o.values[path]
+	if ok {
+		o.seen[path] = true
+	}
+	return value, ok
+}
+
+func (o *defaultsOverlay) skip(path string) {
+	o.seen[path] = true
+}
+
+// check return an error listing the paths that no field consumed
+func (o *defaultsOverlay) check() error {
+	var unknown []string
+	for path := range o.values {
+		if !o.objects[path] && !o.covered(path) {
+			unknown = append(unknown, path)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("unknown paths in defaults %s: %s", o.name, strings.Join(unknown, ", "))
+}
+
+// covered report whether the path or one of its ancestors was seen
+func (o *defaultsOverlay) covered(path string) bool {
+	for {
+		if o.seen[path] {
+			return true
+		}
+		i := strings.LastIndexByte(path, '.')
+		if i < 0 {
+			return false
+		}
+		path = path[:i]
+	}
+}
+
+// flattenJSON flatten a JSON object to values keyed by dotted path
+func flattenJSON(prefix string, b []byte, values map[string]string, objects map[string]bool) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(b, &object); err != nil {
+		return err
+	}
+	for key, raw := range object {
+		p := path(prefix, key)
+		raw = bytes.TrimSpace(raw)
+		switch {
+		case bytes.Equal(raw, []byte("null")):
+			continue
+		case raw[0] == '{':
+			values[p] = JSONPrefix + string(raw)
+			objects[p] = true
+			if err := flattenJSON(p, raw, values, objects); err != nil {
+				return err
+			}
+		case raw[0] == '[':
+			values[p] = JSONPrefix + string(raw)
+		case raw[0] == '"':
+			var s string
+			if err := json.Unmarshal(raw, &s); err != nil {
+				return err
+			}
+			values[p] = s
+		default:
+			values[p] = string(raw) // numbers and booleans
+		}
+	}
+	return nil
+}
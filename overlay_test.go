@@ -0,0 +1,116 @@
+package go_default
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type OverlayServer struct {
+	Host    string        `default:"localhost"`
+	Port    int           `default:"8080"`
+	Timeout time.Duration `default:"1s"`
+	Tags    []string      `default:"a,sep=','"`
+	Labels  map[string]string
+}
+
+type OverlayConfig struct {
+	Name    string         `default:"app"`
+	Debug   bool           `default:"false"`
+	Server  OverlayServer  `default:"dive"`
+	Backup  *OverlayServer `default:"dive"`
+	Ignored string         `default:"-"`
+	Plain   string
+}
+
+func TestStruct_DefaultsJSON(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		var cfg OverlayConfig
+		err := Struct(&cfg, WithDefaultsJSON(strings.NewReader(`{
+			"Name": "svc",
+			"Debug": true,
+			"Plain": "plain",
+			"Server.Port": "9000",
+			"Server": {
+				"Timeout": "5s",
+				"Tags": "x,y",
+				"Labels": {"team": "infra"}
+			},
+			"Backup": {"Host": "backup.local", "Port": 9001, "Tags": ["z"]},
+			"Ignored": "value"
+		}`)))
+		require.NoError(t, err)
+		require.EqualValues(t, "svc", cfg.Name)
+		require.EqualValues(t, true, cfg.Debug)
+		require.EqualValues(t, "plain", cfg.Plain)
+		require.EqualValues(t, OverlayServer{
+			Host:    "localhost",
+			Port:    9000,
+			Timeout: 5 * time.Second,
+			Tags:    []string{"x", "y"},
+			Labels:  map[string]string{"team": "infra"},
+		}, cfg.Server)
+		require.EqualValues(t, OverlayServer{
+			Host:    "backup.local",
+			Port:    9001,
+			Timeout: time.Second,
+			Tags:    []string{"z"},
+		}, *cfg.Backup)
+		require.EqualValues(t, "", cfg.Ignored)
+	})
+	t.Run("not set", func(t *testing.T) {
+		cfg := OverlayConfig{Server: OverlayServer{Port: 1}}
+		err := Struct(&cfg, WithDefaultsJSON(strings.NewReader(`{"Server.Port": 9000}`)))
+		require.NoError(t, err)
+		require.EqualValues(t, 1, cfg.Server.Port)
+	})
+	t.Run("later overlay wins", func(t *testing.T) {
+		var cfg OverlayConfig
+		err := Struct(&cfg,
+			WithDefaultsJSON(strings.NewReader(`{"Name": "first"}`)),
+			WithDefaultsJSON(strings.NewReader(`{"Name": "second"}`)),
+		)
+		require.NoError(t, err)
+		require.EqualValues(t, "second", cfg.Name)
+	})
+	t.Run("should return error when path is unknown", func(t *testing.T) {
+		var cfg OverlayConfig
+		err := Struct(&cfg, WithDefaultsJSON(strings.NewReader(`{"Server": {"Prot": 9000}, "Nmae": "svc"}`)))
+		require.EqualError(t, err, "unknown paths in defaults JSON: Nmae, Server.Prot")
+	})
+	t.Run("should return error when value fails to parse", func(t *testing.T) {
+		var cfg OverlayConfig
+		err := Struct(&cfg, WithDefaultsJSON(strings.NewReader(`{"Server.Port": "http"}`)))
+		require.ErrorContains(t, err, "cannot set default value for Server.Port, parse http to int failed")
+	})
+	t.Run("should return error when JSON is malformed", func(t *testing.T) {
+		var cfg OverlayConfig
+		err := Struct(&cfg, WithDefaultsJSON(strings.NewReader(`["Name"]`)))
+		require.ErrorContains(t, err, "parse defaults JSON failed")
+	})
+}
+
+func TestStruct_DefaultsFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "defaults.json")
+	require.NoError(t, os.WriteFile(name, []byte(`{"Server": {"Port": 7000}}`), 0600))
+
+	var cfg OverlayConfig
+	err := Struct(&cfg, WithDefaultsFile(name))
+	require.NoError(t, err)
+	require.EqualValues(t, 7000, cfg.Server.Port)
+
+	err = Struct(&cfg, WithDefaultsFile(filepath.Join(t.TempDir(), "missing.json")))
+	require.ErrorContains(t, err, "read defaults file")
+
+	// read when applied
+	name = filepath.Join(t.TempDir(), "later.json")
+	opt := WithDefaultsFile(name)
+	require.NoError(t, os.WriteFile(name, []byte(`{"Server": {"Port": 7100}}`), 0600))
+	cfg = OverlayConfig{}
+	require.NoError(t, Struct(&cfg, opt))
+	require.EqualValues(t, 7100, cfg.Server.Port)
+}
@@ -0,0 +1,232 @@
+package go_default
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RefPrefix marks a default value as the value of another field, like `$ref:Server.Port`
+//
+// A value can also interpolate other fields, like `http://${.Server.Host}:${.Server.Port}`. The references are
+// resolved once the whole struct is filled, so they see the defaults of the referenced fields, in dependency order.
+const RefPrefix = "$ref:"
+
+var refPattern = regexp.MustCompile(`\$\{\.([^}]*)\}`)
+
+// reference is a field whose default value refers to other fields
+type reference struct {
+	path string
+	tag  Tag
+	refs []string
+}
+
+// parseRefs return the paths referred to by an unquoted tag value
+func parseRefs(tag Tag) ([]string, bool) {
+	if tag.Quoted {
+		return nil, false
+	}
+	if strings.HasPrefix(tag.Value, RefPrefix) {
+		return []string{strings.TrimPrefix(tag.Value, RefPrefix)}, true
+	}
+	var refs []string
+	for _, match := range refPattern.FindAllStringSubmatch(tag.Value, -1) {
+		refs = append(refs, match[1])
+	}
+	return refs, len(refs) > 0
+}
+
+// resolveRefs set the fields referring to other fields, the referred ones first
+func (cfg *Config) resolveRefs(root reflect.Value) error {
+	refs := cfg.refs
+	cfg.refs = nil
+	cfg.resolved = append(cfg.resolved, refs...)
+	if len(refs) == 0 {
+		return nil
+	}
+
+	const (
+		visiting = 1
+		resolved = 2
+	)
+	state := map[*reference]int{}
+	var stack []string
+	var visit func(r *reference) error
+	visit = func(r *reference) error {
+		switch state[r] {
+		case resolved:
+			return nil
+		case visiting:
+			i := 0
+			for stack[i] != r.path {
+				i++
+			}
+			cycle := append(stack[i:], r.path)
+			return fmt.Errorf("cannot set default value for %s, reference cycle %s", r.path, strings.Join(cycle, " -> "))
+		}
+		state[r] = visiting
+		stack = append(stack, r.path)
+		for _, ref := range r.refs {
+			for _, dep := range refs {
+				if dep.path == ref || isSubpath(dep.path, ref) || isSubpath(ref, dep.path) {
+					if err := visit(dep); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[r] = resolved
+		return cfg.setRef(root, r)
+	}
+	for _, r := range refs {
+		if err := visit(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setRef set the value of a reference, a nil pointer or a missing element on the way leaves the field unset
+func (cfg *Config) setRef(root reflect.Value, r *reference) error {
+	fieldValue, ok, err := walkPath(root, r.path, cfg)
+	if err != nil || !ok {
+		return err
+	}
+	if !fieldValue.CanSet() {
+		return fmt.Errorf("cannot set default value for %s, references are not supported in map values", r.path)
+	}
+
+	if strings.HasPrefix(r.tag.Value, RefPrefix) {
+		target := strings.TrimPrefix(r.tag.Value, RefPrefix)
+		value, ok, err := walkPath(root, target, cfg)
+		if err != nil || !ok {
+			return err
+		}
+		if value.Type() == fieldValue.Type() {
+			fieldValue.Set(copyValue(value))
+		} else if err := fillSome(r.path, fieldValue, Tag{Value: formatValue(value), Quoted: true, Options: r.tag.Options}, cfg); err != nil {
+			return err
+		}
+		cfg.record(r.path, "ref "+target)
+		return nil
+	}
+
+	var walkErr error
+	found := true
+	value := refPattern.ReplaceAllStringFunc(r.tag.Value, func(match string) string {
+		target := refPattern.FindStringSubmatch(match)[1]
+		value, ok, err := walkPath(root, target, cfg)
+		if err != nil {
+			walkErr = err
+		}
+		if err != nil || !ok {
+			found = false
+			return ""
+		}
+		return formatValue(value)
+	})
+	if walkErr != nil || !found {
+		return walkErr
+	}
+	if cfg.LookupEnv != nil {
+		if value, err = ExpandEnv(value, cfg.LookupEnv); err != nil {
+			return fmt.Errorf("cannot set default value for %s, expand %s failed: %w", r.path, value, err)
+		}
+	}
+	if value, err = resolve(r.path, value, cfg); err != nil {
+		return err
+	}
+	if err := fillSome(r.path, fieldValue, Tag{Value: value, Quoted: true, Options: r.tag.Options}, cfg); err != nil {
+		return err
+	}
+	cfg.record(r.path, "ref")
+	return nil
+}
+
+// checkRefs check the paths referred to by a tag, whether or not the field is set, so a typo is always reported
+func (cfg *Config) checkRefs(tag Tag) error {
+	refs, ok := parseRefs(tag)
+	if !ok || cfg.root == nil {
+		return nil
+	}
+	for _, ref := range refs {
+		if err := checkRef(cfg.root, ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRef check that a referenced path exists in the type of root, a pointer to a struct
+func checkRef(t reflect.Type, ref string) error {
+	steps, err := parsePath(ref)
+	if err != nil {
+		return fmt.Errorf("unknown reference %s", ref)
+	}
+	for _, step := range steps {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		switch {
+		case !step.isIndex && t.Kind() == reflect.Struct:
+			field, ok := t.FieldByName(step.name)
+			if !ok || !field.IsExported() {
+				return fmt.Errorf("unknown reference %s", ref)
+			}
+			t = field.Type
+		case step.isIndex && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map):
+			t = t.Elem()
+		default:
+			return fmt.Errorf("unknown reference %s", ref)
+		}
+	}
+	return nil
+}
+
+// walkPath return the value at a path under root without allocating, and false if a nil pointer or a missing element
+// is on the way
+func walkPath(root reflect.Value, path string, cfg *Config) (reflect.Value, bool, error) {
+	steps, err := parsePath(path)
+	if err != nil {
+		return reflect.Value{}, false, fmt.Errorf("cannot set default value for %s, %w", path, err)
+	}
+	v := root
+	for _, step := range steps {
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false, nil
+			}
+			v = v.Elem()
+		}
+		switch {
+		case !step.isIndex && v.Kind() == reflect.Struct:
+			field, ok := v.Type().FieldByName(step.name)
+			if !ok || !field.IsExported() {
+				return reflect.Value{}, false, fmt.Errorf("cannot set default value for %s, unknown path %s", path, path)
+			}
+			if v, err = v.FieldByIndexErr(field.Index); err != nil {
+				return reflect.Value{}, false, nil
+			}
+		case step.isIndex && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array):
+			i, err := strconv.Atoi(step.index)
+			if err != nil || i < 0 || i >= v.Len() {
+				return reflect.Value{}, false, nil
+			}
+			v = v.Index(i)
+		case step.isIndex && v.Kind() == reflect.Map:
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := fillSome(path, key, Tag{Value: step.index, Quoted: true}, cfg); err != nil {
+				return reflect.Value{}, false, err
+			}
+			if v = v.MapIndex(key); !v.IsValid() {
+				return reflect.Value{}, false, nil
+			}
+		default:
+			return reflect.Value{}, false, fmt.Errorf("cannot set default value for %s, unknown path %s", path, path)
+		}
+	}
+	return v, true, nil
+}
@@ -0,0 +1,114 @@
+package go_default
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type RefServer struct {
+	Host      string        `default:"localhost"`
+	Port      int           `default:"8080"`
+	AdminPort int           `default:"$ref:Server.Port"`
+	URL       string        `default:"http://${.Server.Host}:${.Server.Port}"`
+	Timeout   time.Duration `default:"$ref:Defaults.Timeout"`
+	Tags      []string      `default:"$ref:Defaults.Tags"`
+}
+
+type RefDefaults struct {
+	Timeout time.Duration `default:"5s"`
+	Tags    []string      `default:"a,b,sep=','"`
+}
+
+type RefConfig struct {
+	// Backup is declared before the referenced fields, so the references are ordered by dependency
+	Backup   RefServer   `default:"dive"`
+	Server   RefServer   `default:"dive"`
+	Defaults RefDefaults `default:"dive"`
+	Port     int64       `default:"$ref:Backup.AdminPort"`
+	Label    string      `default:"${.Server.URL}/${.Missing.Host}"`
+	Missing  *RefServer
+	Quoted   string `default:"'$ref:Server.Port'"`
+}
+
+func (c *RefConfig) Validate() error {
+	if c.Port == 0 {
+		return errors.New("port is required")
+	}
+	return nil
+}
+
+func TestStruct_Ref(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		var cfg RefConfig
+		p := Provenance{}
+		require.NoError(t, Struct(&cfg, WithProvenance(p)))
+		require.EqualValues(t, 8080, cfg.Server.AdminPort)
+		require.EqualValues(t, "http://localhost:8080", cfg.Server.URL)
+		require.EqualValues(t, 5*time.Second, cfg.Server.Timeout)
+		require.EqualValues(t, []string{"a", "b"}, cfg.Server.Tags)
+		require.EqualValues(t, 8080, cfg.Backup.AdminPort)
+		require.EqualValues(t, 8080, cfg.Port)
+		require.Empty(t, cfg.Label)
+		require.EqualValues(t, "$ref:Server.Port", cfg.Quoted)
+		require.EqualValues(t, "ref Server.Port", p["Server.AdminPort"])
+		require.EqualValues(t, "ref", p["Server.URL"])
+
+		// copied, not shared
+		cfg.Server.Tags[0] = "changed"
+		require.EqualValues(t, "a", cfg.Defaults.Tags[0])
+	})
+	t.Run("effective value", func(t *testing.T) {
+		cfg := RefConfig{Server: RefServer{Port: 9000, AdminPort: 9001}}
+		require.NoError(t, Struct(&cfg, WithSources(MapSource{"Server.Host": "example.com"})))
+		require.EqualValues(t, 9001, cfg.Server.AdminPort)
+		require.EqualValues(t, "http://example.com:9000", cfg.Server.URL)
+		require.EqualValues(t, 9000, cfg.Backup.AdminPort)
+	})
+	t.Run("cycle", func(t *testing.T) {
+		var cfg struct {
+			A int `default:"$ref:B"`
+			B int `default:"$ref:C"`
+			C int `default:"${.A}"`
+		}
+		require.EqualError(t, Struct(&cfg), "cannot set default value for A, reference cycle A -> B -> C -> A")
+	})
+	t.Run("self", func(t *testing.T) {
+		var cfg struct {
+			A int `default:"$ref:A"`
+		}
+		require.EqualError(t, Struct(&cfg), "cannot set default value for A, reference cycle A -> A")
+	})
+	t.Run("unknown path", func(t *testing.T) {
+		var cfg struct {
+			Server RefDefaults `default:"dive"`
+			A      int         `default:"$ref:Server.Nope"`
+		}
+		require.EqualError(t, Struct(&cfg), "cannot set default value for A, unknown reference Server.Nope")
+
+		var interpolated struct {
+			A string `default:"${.B.C}"`
+			B string
+		}
+		require.EqualError(t, Struct(&interpolated), "cannot set default value for A, unknown reference B.C")
+
+		// reported even if the field is set
+		var set struct {
+			A int `default:"$ref:Nope"`
+		}
+		set.A = 1
+		require.EqualError(t, Struct(&set), "cannot set default value for A, unknown reference Nope")
+		err := Struct(&set, WithSources(MapSource{"A": "2"}))
+		require.EqualError(t, err, "cannot set default value for A, unknown reference Nope")
+	})
+	t.Run("convert", func(t *testing.T) {
+		var cfg struct {
+			Port    string `default:"8080"`
+			PortNum uint16 `default:"$ref:Port"`
+		}
+		require.NoError(t, Struct(&cfg))
+		require.EqualValues(t, 8080, cfg.PortNum)
+	})
+}
@@ -0,0 +1,141 @@
+package go_default
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/fs"
+	"os"
+	slashpath "path"
+	"strings"
+	"sync"
+)
+
+// Resolver resolve the value after a scheme prefix, like "./cert.pem" of "file:./cert.pem", to the raw default value
+//
+// The raw value is then converted by the setters like a tag value.
+type Resolver func(value string) (string, error)
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = map[string]Resolver{}
+)
+
+// RegisterResolver register a resolver for a scheme, like "secret" for "secret:db-password"
+//
+// The registered resolvers are used by every call with resolvers enabled, see WithResolvers.
+func RegisterResolver(scheme string, resolver Resolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers[scheme] = resolver
+}
+
+// DefaultResolvers return the built-in resolvers
+//
+//   - "file:" read a file, like "file:./cert.pem"
+//   - "base64:" decode a standard base64 string, like "base64:SGVsbG8="
+//   - "env:" read an environment variable, like "env:DB_PASSWORD", by the lookup of WithLookupEnv if any once enabled
+func DefaultResolvers() map[string]Resolver {
+	return map[string]Resolver{
+		"file":   FileResolver(nil),
+		"base64": Base64Resolver,
+		"env":    EnvResolver,
+	}
+}
+
+// FileResolver return a resolver that reads files from fsys, or from the OS filesystem if fsys is nil
+func FileResolver(fsys fs.FS) Resolver {
+	return func(value string) (string, error) {
+		var b []byte
+		var err error
+		if fsys == nil {
+			b, err = os.ReadFile(value)
+		} else {
+			b, err = fs.ReadFile(fsys, slashpath.Clean(value))
+		}
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+}
+
+// Base64Resolver decode a standard base64 string
+func Base64Resolver(value string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// EnvResolver read an environment variable, it fails if the variable is not set
+func EnvResolver(value string) (string, error) {
+	return LookupEnvResolver(os.LookupEnv)(value)
+}
+
+// LookupEnvResolver return a resolver that reads variables by lookup, it fails if the variable is not set
+func LookupEnvResolver(lookup LookupEnvFunc) Resolver {
+	return func(value string) (string, error) {
+		v, ok := lookup(value)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", value)
+		}
+		return v, nil
+	}
+}
+
+// WithResolvers enable the default resolvers and the registered ones, so values like "file:./cert.pem" are resolved
+func WithResolvers() Option {
+	return func(cfg *Config) {
+		enableResolvers(cfg)
+	}
+}
+
+// WithResolver enable the resolvers and set the resolver for a scheme
+func WithResolver(scheme string, resolver Resolver) Option {
+	return func(cfg *Config) {
+		enableResolvers(cfg)
+		cfg.Resolvers[scheme] = resolver
+	}
+}
+
+// WithFileSystem enable the resolvers and read the "file:" values from fsys, like an embed.FS
+func WithFileSystem(fsys fs.FS) Option {
+	return WithResolver("file", FileResolver(fsys))
+}
+
+func enableResolvers(cfg *Config) {
+	if cfg.Resolvers != nil {
+		return
+	}
+	cfg.Resolvers = DefaultResolvers()
+	cfg.Resolvers["env"] = func(value string) (string, error) {
+		// looked up when resolved, as WithLookupEnv may come after
+		if cfg.LookupEnv != nil {
+			return LookupEnvResolver(cfg.LookupEnv)(value)
+		}
+		return EnvResolver(value)
+	}
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	for scheme, resolver := range resolvers {
+		cfg.Resolvers[scheme] = resolver
+	}
+}
+
+// resolve resolve a value with a registered scheme prefix, other values are returned as is
+func resolve(path string, value string, cfg *Config) (string, error) {
+	scheme, rest, ok := strings.Cut(value, ":")
+	if !ok {
+		return value, nil
+	}
+	resolver, ok := cfg.Resolvers[scheme]
+	if !ok {
+		return value, nil
+	}
+	resolved, err := resolver(rest)
+	if err != nil {
+		return "", fmt.Errorf("cannot set default value for %s, resolve %s failed: %w", path, value, err)
+	}
+	return resolved, nil
+}
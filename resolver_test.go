@@ -0,0 +1,122 @@
+package go_default
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStruct_Resolvers(t *testing.T) {
+	fsys := fstest.MapFS{
+		"certs/server.pem": &fstest.MapFile{Data: []byte("-----BEGIN CERTIFICATE-----\n")},
+		"sql/query.sql":    &fstest.MapFile{Data: []byte("SELECT 1; SELECT 2")},
+		"port":             &fstest.MapFile{Data: []byte("8443")},
+	}
+
+	t.Run("set", func(t *testing.T) {
+		t.Setenv("GO_DEFAULT_TEST_PASSWORD", "hunter2")
+		var foo struct {
+			Cert     string  `default:"file:./certs/server.pem"`
+			Query    string  `default:"file:sql/query.sql"`
+			Port     int     `default:"file:port"`
+			Greeting string  `default:"base64:SGVsbG8="`
+			Password *string `default:"env:GO_DEFAULT_TEST_PASSWORD"`
+			Secret   string  `default:"secret:db"`
+			Unknown  string  `default:"other:value"`
+		}
+		err := Struct(&foo,
+			WithFileSystem(fsys),
+			WithResolver("secret", func(value string) (string, error) {
+				return "s3cr3t-" + value, nil
+			}),
+		)
+		require.NoError(t, err)
+		require.EqualValues(t, "-----BEGIN CERTIFICATE-----\n", foo.Cert)
+		require.EqualValues(t, "SELECT 1; SELECT 2", foo.Query)
+		require.EqualValues(t, 8443, foo.Port)
+		require.EqualValues(t, "Hello", foo.Greeting)
+		require.EqualValues(t, "hunter2", *foo.Password)
+		require.EqualValues(t, "s3cr3t-db", foo.Secret)
+		require.EqualValues(t, "other:value", foo.Unknown)
+	})
+	t.Run("os filesystem", func(t *testing.T) {
+		name := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(name, []byte("token-value"), 0600))
+		var foo struct {
+			Token string `default:"file:${TOKEN_FILE}"`
+		}
+		err := Struct(&foo, WithResolvers(), WithLookupEnv(MapLookupEnv(map[string]string{"TOKEN_FILE": name})))
+		require.NoError(t, err)
+		require.EqualValues(t, "token-value", foo.Token)
+	})
+	t.Run("injected lookup", func(t *testing.T) {
+		var foo struct {
+			Password string `default:"env:DB_PASSWORD"`
+		}
+		err := Struct(&foo, WithResolvers(), WithLookupEnv(MapLookupEnv(map[string]string{"DB_PASSWORD": "hunter2"})))
+		require.NoError(t, err)
+		require.EqualValues(t, "hunter2", foo.Password)
+	})
+	t.Run("flag value", func(t *testing.T) {
+		var foo struct {
+			Cert string `default:"none"`
+		}
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		require.NoError(t, RegisterFlags(fs, &foo, WithFileSystem(fsys)))
+		require.NoError(t, fs.Parse([]string{"-cert=file:certs/server.pem"}))
+		require.EqualValues(t, "-----BEGIN CERTIFICATE-----\n", foo.Cert)
+	})
+	t.Run("registered resolver", func(t *testing.T) {
+		RegisterResolver("upper-test", func(value string) (string, error) {
+			return "UPPER:" + value, nil
+		})
+		var foo struct {
+			Value string `default:"upper-test:x"`
+		}
+		err := Struct(&foo, WithResolvers())
+		require.NoError(t, err)
+		require.EqualValues(t, "UPPER:x", foo.Value)
+	})
+	t.Run("override value", func(t *testing.T) {
+		var foo struct {
+			Cert string `default:"none"`
+		}
+		err := Struct(&foo,
+			WithFileSystem(fsys),
+			WithEnv(WithEnvLookup(MapLookupEnv(map[string]string{"CERT": "file:certs/server.pem"}))),
+		)
+		require.NoError(t, err)
+		require.EqualValues(t, "-----BEGIN CERTIFICATE-----\n", foo.Cert)
+	})
+	t.Run("not resolved without option", func(t *testing.T) {
+		var foo struct {
+			Value string `default:"base64:SGVsbG8="`
+		}
+		err := Struct(&foo)
+		require.NoError(t, err)
+		require.EqualValues(t, "base64:SGVsbG8=", foo.Value)
+	})
+	t.Run("should return error with field path when resolver fails", func(t *testing.T) {
+		var foo struct {
+			Nested struct {
+				Cert   string `default:"file:missing.pem"`
+				Secret string `default:"secret:db"`
+			} `default:"dive"`
+		}
+		err := Struct(&foo, WithFileSystem(fsys))
+		require.ErrorContains(t, err, "cannot set default value for Nested.Cert, resolve file:missing.pem failed")
+
+		errSecret := errors.New("vault is sealed")
+		foo.Nested.Cert = "set"
+		err = Struct(&foo, WithResolver("secret", func(value string) (string, error) {
+			return "", errSecret
+		}))
+		require.ErrorIs(t, err, errSecret)
+		require.ErrorContains(t, err, "cannot set default value for Nested.Secret, resolve secret:db failed: vault is sealed")
+	})
+}
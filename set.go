@@ -0,0 +1,200 @@
+package go_default
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// SourceSet is the name of the values set by SetValues in the Provenance
+const SourceSet = "set"
+
+// Assignments collect repeated "path=value" flags, like "--set Server.Port=9000", for SetValues
+//
+//	var sets godefault.Assignments
+//	flag.Var(&sets, "set", "override a value, like Server.Port=9000")
+type Assignments []string
+
+func (a *Assignments) String() string {
+	if a == nil {
+		return ""
+	}
+	return strings.Join(*a, ",")
+}
+
+func (a *Assignments) Set(value string) error {
+	*a = append(*a, value)
+	return nil
+}
+
+// SetValues set the values of "path=value" assignments, like "Server.Port=9000", into input
+//
+// The paths can index slices, arrays and maps, like "Servers[0].Port" and "Labels[env]". An index equal to the
+// length of a slice appends an element. The values replace the current values and are converted by the same setters
+// as the tag values.
+func SetValues(input any, assignments []string, opts ...Option) error {
+	cfg := newConfig(opts...)
+	if len(cfg.errs) > 0 {
+		return cfg.errs[0]
+	}
+	v := reflect.ValueOf(input)
+	if !isStructPointer(v) {
+		return ErrNotPointer
+	}
+	cfg.root = v.Type()
+
+	for _, assignment := range assignments {
+		path, value, ok := cutAssignment(assignment)
+		if !ok || path == "" {
+			return fmt.Errorf("invalid assignment %s, expect path=value", assignment)
+		}
+		steps, err := parsePath(path)
+		if err != nil {
+			return fmt.Errorf("cannot set default value for %s, %w", path, err)
+		}
+		if value, err = resolve(path, value, cfg); err != nil {
+			return err
+		}
+		if err := assign(path, "", v.Elem(), steps, Tag{Value: value, Quoted: true}, cfg); err != nil {
+			return err
+		}
+		cfg.record(path, SourceSet)
+	}
+	return cfg.finish(v)
+}
+
+// cutAssignment cut an assignment at the first "=" outside of the indexes, so map keys can contain "="
+func cutAssignment(assignment string) (path, value string, ok bool) {
+	inIndex := false
+	for i := 0; i < len(assignment); i++ {
+		switch assignment[i] {
+		case '[':
+			inIndex = true
+		case ']':
+			inIndex = false
+		case '=':
+			if !inIndex {
+				return assignment[:i], assignment[i+1:], true
+			}
+		}
+	}
+	return assignment, "", false
+}
+
+// pathStep is a field name, or an index of a slice, an array or a map
+type pathStep struct {
+	name    string
+	index   string
+	isIndex bool
+}
+
+// parsePath split a path like "Servers[0].Port" to steps
+func parsePath(path string) ([]pathStep, error) {
+	var steps []pathStep
+	for i := 0; i < len(path); {
+		if path[i] == '[' {
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated index")
+			}
+			steps = append(steps, pathStep{index: path[i+1 : i+end], isIndex: true})
+			i += end + 1
+		} else {
+			if len(steps) > 0 {
+				if path[i] != '.' {
+					return nil, fmt.Errorf("invalid path")
+				}
+				i++
+			}
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid path")
+			}
+			steps = append(steps, pathStep{name: path[i : i+end]})
+			i += end
+		}
+	}
+	if len(steps) == 0 || steps[0].isIndex {
+		return nil, fmt.Errorf("invalid path")
+	}
+	return steps, nil
+}
+
+// assign walk the steps from v, allocating the nil pointers, maps and appended elements, and fill the last one
+func assign(path string, walked string, v reflect.Value, steps []pathStep, tag Tag, cfg *Config) error {
+	if len(steps) == 0 {
+		v.Set(reflect.Zero(v.Type()))
+		return fillSome(path, v, tag, cfg)
+	}
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	step := steps[0]
+	if !step.isIndex {
+		if walked != "" {
+			walked += "."
+		}
+		walked += step.name
+		if v.Kind() != reflect.Struct {
+			return fmt.Errorf("cannot set default value for %s, unknown path %s", path, walked)
+		}
+		field, ok := v.Type().FieldByName(step.name)
+		if !ok || !field.IsExported() {
+			return fmt.Errorf("cannot set default value for %s, unknown path %s", path, walked)
+		}
+		if len(steps) == 1 {
+			tagValue := tagValueOf(v.Type(), field, cfg)
+			fieldTag, err := ParseTag(tagValue)
+			if err != nil {
+				return fmt.Errorf("cannot set default value for %s, parse tag %s failed: %w", path, tagValue, err)
+			}
+			tag.Options = fieldTag.Options
+		}
+		fieldValue, err := v.FieldByIndexErr(field.Index)
+		if err != nil {
+			return fmt.Errorf("cannot set default value for %s, %w", path, err)
+		}
+		return assign(path, walked, fieldValue, steps[1:], tag, cfg)
+	}
+
+	walked += "[" + step.index + "]"
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		i, err := strconv.Atoi(step.index)
+		if err != nil || i < 0 || i > v.Len() || i == v.Len() && v.Kind() == reflect.Array {
+			return fmt.Errorf("cannot set default value for %s, index out of range %s", path, walked)
+		}
+		if i == v.Len() {
+			v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+		}
+		return assign(path, walked, v.Index(i), steps[1:], Tag{Value: tag.Value, Quoted: true}, cfg)
+	case reflect.Map:
+		key := reflect.New(v.Type().Key()).Elem()
+		if err := fillSome(walked, key, Tag{Value: step.index, Quoted: true}, cfg); err != nil {
+			return err
+		}
+		// map elements are not addressable, so fill a copy and put it back
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if current := v.MapIndex(key); current.IsValid() {
+			elem.Set(current)
+		}
+		if err := assign(path, walked, elem, steps[1:], Tag{Value: tag.Value, Quoted: true}, cfg); err != nil {
+			return err
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		v.SetMapIndex(key, elem)
+		return nil
+	default:
+		return fmt.Errorf("cannot set default value for %s, unknown path %s", path, walked)
+	}
+}
@@ -0,0 +1,88 @@
+package go_default
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type SetConfig struct {
+	Name    string
+	Server  *EnvServer
+	Servers []EnvServer
+	Ports   [2]int
+	Labels  map[string]string
+	Limits  map[string]*EnvServer
+	Weights map[int]float64
+	Tags    []string `default:"a,sep=','"`
+}
+
+func TestSetValues(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		cfg := SetConfig{Name: "current", Servers: []EnvServer{{Port: 1}}}
+		p := Provenance{}
+		require.NoError(t, SetValues(&cfg, []string{
+			"Name=app",
+			"Server.Port=9000",
+			"Server.Timeout=5s",
+			"Servers[0].Host=first.local",
+			"Servers[1].Port=2",
+			"Ports[1]=8080",
+			"Labels[env]=prod",
+			"Labels[a.b]=dotted",
+			"Labels[k=v]=equal",
+			"Limits[api].MaxConns=10",
+			"Weights[3]=0.5",
+			"Tags=x,y",
+			"Server.Tags=a=b",
+		}, WithProvenance(p)))
+		require.EqualValues(t, "app", cfg.Name)
+		require.EqualValues(t, 9000, cfg.Server.Port)
+		require.EqualValues(t, 5*time.Second, cfg.Server.Timeout)
+		require.EqualValues(t, []EnvServer{{Host: "first.local", Port: 1}, {Port: 2}}, cfg.Servers)
+		require.EqualValues(t, [2]int{0, 8080}, cfg.Ports)
+		require.EqualValues(t, map[string]string{"env": "prod", "a.b": "dotted", "k=v": "equal"}, cfg.Labels)
+		require.EqualValues(t, 10, cfg.Limits["api"].MaxConns)
+		require.EqualValues(t, map[int]float64{3: 0.5}, cfg.Weights)
+		require.EqualValues(t, []string{"x", "y"}, cfg.Tags)
+		require.EqualValues(t, []string{"a=b"}, cfg.Server.Tags)
+		require.EqualValues(t, SourceSet, p["Server.Port"])
+	})
+	t.Run("flag", func(t *testing.T) {
+		var sets Assignments
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.Var(&sets, "set", "")
+		require.NoError(t, fs.Parse([]string{"--set", "Name=app", "--set", "Server.Port=9000"}))
+		require.EqualValues(t, Assignments{"Name=app", "Server.Port=9000"}, sets)
+
+		var cfg SetConfig
+		require.NoError(t, SetValues(&cfg, sets))
+		require.EqualValues(t, "app", cfg.Name)
+		require.EqualValues(t, 9000, cfg.Server.Port)
+	})
+	t.Run("errors", func(t *testing.T) {
+		for assignment, msg := range map[string]string{
+			"Name":                "invalid assignment Name, expect path=value",
+			"=x":                  "invalid assignment =x, expect path=value",
+			"Server..Port=1":      "cannot set default value for Server..Port, invalid path",
+			"Servers[0.Port":      "invalid assignment Servers[0.Port, expect path=value",
+			"Servers[0.Port]=1":   "cannot set default value for Servers[0.Port], index out of range Servers[0.Port]",
+			"Nope=1":              "cannot set default value for Nope, unknown path Nope",
+			"Server.Nope.Port=1":  "cannot set default value for Server.Nope.Port, unknown path Server.Nope",
+			"Name.Port=1":         "cannot set default value for Name.Port, unknown path Name.Port",
+			"Servers[5].Port=1":   "cannot set default value for Servers[5].Port, index out of range Servers[5]",
+			"Ports[2]=1":          "cannot set default value for Ports[2], index out of range Ports[2]",
+			"Weights[x]=1":        "cannot set default value for Weights[x], parse x to int failed",
+			"Server.Port=abc":     "cannot set default value for Server.Port, parse abc to int failed",
+			"Server.Timeout=1abc": "cannot set default value for Server.Timeout",
+		} {
+			var cfg SetConfig
+			require.ErrorContains(t, SetValues(&cfg, []string{assignment}), msg, assignment)
+		}
+	})
+	t.Run("not pointer", func(t *testing.T) {
+		require.ErrorIs(t, SetValues(SetConfig{}, nil), ErrNotPointer)
+	})
+}
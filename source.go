@@ -0,0 +1,176 @@
+package go_default
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// SourceTag is the name of the tag values in the Provenance
+const SourceTag = "tag"
+
+// Source look up the raw value of a field by its path, like "Server.Port"
+//
+// The values are converted by the same setters as the tag values. A source can implement fmt.Stringer to name itself
+// in the Provenance, and Err() error to report a failure of its construction.
+type Source interface {
+	Lookup(path string) (string, bool)
+}
+
+// FieldSource is a Source that also looks up by the struct field, e.g. to read a tag of the field
+type FieldSource interface {
+	Source
+	LookupField(path string, field reflect.StructField) (string, bool)
+}
+
+// defaultedSource is a FieldSource that is also told whether the field has a default value, to skip the others
+type defaultedSource interface {
+	lookupDefaulted(path string, field reflect.StructField, hasDefault bool) (string, bool)
+}
+
+// loader is a Source that reads its values when Struct applies it, like the dotenv files of an EnvSource
+type loader interface {
+	load() error
+}
+
+// Provenance record the name of the source that supplied the final value of every field set, keyed by path
+type Provenance map[string]string
+
+// SourceName return the name of a source, by its String method if any
+func SourceName(src Source) string {
+	if stringer, ok := src.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return fmt.Sprintf("%T", src)
+}
+
+// WithSources override the tag values by the sources, later sources take precedence over earlier ones
+//
+// Sources replace the current value of a field, while the tag values only fill zero fields.
+func WithSources(sources ...Source) Option {
+	return func(cfg *Config) {
+		for _, src := range sources {
+			if errSource, ok := src.(interface{ Err() error }); ok {
+				if err := errSource.Err(); err != nil {
+					cfg.errs = append(cfg.errs, err)
+				}
+			}
+			if loader, ok := src.(loader); ok {
+				if err := loader.load(); err != nil {
+					cfg.errs = append(cfg.errs, err)
+				}
+			}
+		}
+		cfg.sources = append(cfg.sources, sources...)
+	}
+}
+
+// WithProvenance record the source of every value set into p
+func WithProvenance(p Provenance) Option {
+	return func(cfg *Config) {
+		cfg.provenance = p
+	}
+}
+
+// Load fill input from the tag values and the sources, later sources take precedence over earlier ones
+//
+// It returns the Provenance of the values, to answer where a value came from.
+// Use Struct with WithSources and WithProvenance for more options.
+func Load(input any, sources ...Source) (Provenance, error) {
+	p := Provenance{}
+	if err := Struct(input, WithSources(sources...), WithProvenance(p)); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (cfg *Config) record(path string, source string) {
+	cfg.applied++
+	if cfg.provenance != nil {
+		cfg.provenance[path] = source
+	}
+}
+
+// MapSource is a Source of explicit values keyed by path
+type MapSource map[string]string
+
+func (m MapSource) Lookup(path string) (string, bool) {
+	value, ok := m[path]
+	return value, ok
+}
+
+func (m MapSource) String() string {
+	return "map"
+}
+
+// JSONSource is a Source of the values of a JSON object keyed by path, see WithDefaultsJSON for the format
+type JSONSource struct {
+	name   string
+	values map[string]string
+}
+
+// NewJSONSource read a JSON object keyed by path, name is the name of the source in the Provenance
+func NewJSONSource(name string, r io.Reader) (*JSONSource, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read %s failed: %w", name, err)
+	}
+	values := map[string]string{}
+	if err := flattenJSON("", b, values, map[string]bool{}); err != nil {
+		return nil, fmt.Errorf("parse %s failed: %w", name, err)
+	}
+	return &JSONSource{name: name, values: values}, nil
+}
+
+// NewJSONFileSource read a JSON file keyed by path, see WithDefaultsJSON for the format
+func NewJSONFileSource(name string) (*JSONSource, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("read %s failed: %w", name, err)
+	}
+	defer f.Close()
+	return NewJSONSource("file "+name, f)
+}
+
+func (src *JSONSource) Lookup(path string) (string, bool) {
+	value, ok := src.values[path]
+	return value, ok
+}
+
+func (src *JSONSource) String() string {
+	return src.name
+}
+
+// FlagSource is a Source of the flags set on the command line, named after the paths like "server.port"
+type FlagSource struct {
+	FlagSet *flag.FlagSet
+}
+
+// NewFlagSource create a FlagSource of a parsed flag set
+func NewFlagSource(fs *flag.FlagSet) *FlagSource {
+	return &FlagSource{FlagSet: fs}
+}
+
+func (src *FlagSource) Lookup(path string) (string, bool) {
+	name := FlagName(path)
+	var value string
+	var found bool
+	src.FlagSet.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			value, found = f.Value.String(), true
+		}
+	})
+	return value, found
+}
+
+func (src *FlagSource) String() string {
+	return "flags"
+}
+
+// FlagName return the flag name of a path, like "server.port" for "Server.Port"
+func FlagName(path string) string {
+	return strings.ToLower(path)
+}
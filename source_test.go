@@ -0,0 +1,109 @@
+package go_default
+
+import (
+	"flag"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	file, err := NewJSONSource("config.json", strings.NewReader(`{"Name": "from-file", "Server": {"Port": 7000, "Host": "file.local"}}`))
+	require.NoError(t, err)
+	env := NewEnvSource(WithEnvPrefix("APP"), WithEnvLookup(MapLookupEnv(map[string]string{
+		"APP_SERVER_PORT": "8000",
+		"APP_SERVER_HOST": "env.local",
+	})))
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("server.port", "", "")
+	fs.String("server.timeout", "", "")
+	require.NoError(t, fs.Parse([]string{"-server.port=9000"}))
+
+	var cfg EnvConfig
+	provenance, err := Load(&cfg, file, env, NewFlagSource(fs), MapSource{"Server.MaxConns": "99"})
+	require.NoError(t, err)
+	require.EqualValues(t, "from-file", cfg.Name)
+	require.EqualValues(t, "env.local", cfg.Server.Host)
+	require.EqualValues(t, 9000, cfg.Server.Port)
+	require.EqualValues(t, 99, cfg.Server.MaxConns)
+	require.EqualValues(t, "1s", cfg.Server.Timeout.String())
+	require.EqualValues(t, Provenance{
+		"Name":            "config.json",
+		"Server.Host":     "env",
+		"Server.Port":     "flags",
+		"Server.MaxConns": "map",
+		"Server.Timeout":  SourceTag,
+		"Server.Tags":     SourceTag,
+		"Backup.Host":     SourceTag,
+		"Backup.Port":     SourceTag,
+		"Backup.MaxConns": SourceTag,
+		"Backup.Timeout":  SourceTag,
+		"Backup.Tags":     SourceTag,
+	}, provenance)
+
+	t.Run("later sources take precedence", func(t *testing.T) {
+		var cfg EnvConfig
+		provenance, err := Load(&cfg, MapSource{"Name": "first"}, MapSource{"Name": "second"})
+		require.NoError(t, err)
+		require.EqualValues(t, "second", cfg.Name)
+		require.EqualValues(t, "map", provenance["Name"])
+	})
+	t.Run("sources override current values", func(t *testing.T) {
+		cfg := EnvConfig{Name: "current", Server: EnvServer{Host: "current.local"}}
+		provenance, err := Load(&cfg, MapSource{"Name": "map"})
+		require.NoError(t, err)
+		require.EqualValues(t, "map", cfg.Name)
+		require.EqualValues(t, "current.local", cfg.Server.Host)
+		require.NotContains(t, provenance, "Server.Host")
+	})
+	t.Run("provenance of values kept by setters", func(t *testing.T) {
+		endpoint, err := url.Parse("https://example.com")
+		require.NoError(t, err)
+		var cfg struct {
+			URL     *url.URL  `default:"http://localhost"`
+			Started time.Time `default:"2025-01-10T00:00:00Z"`
+		}
+		cfg.URL = endpoint
+		provenance := Provenance{}
+		require.NoError(t, Struct(&cfg, WithProvenance(provenance)))
+		require.Same(t, endpoint, cfg.URL)
+		require.NotContains(t, provenance, "URL")
+		require.EqualValues(t, SourceTag, provenance["Started"])
+	})
+	t.Run("provenance of defaults overlay", func(t *testing.T) {
+		var cfg EnvConfig
+		provenance := Provenance{}
+		err := Struct(&cfg, WithDefaultsJSON(strings.NewReader(`{"Name": "overlay"}`)), WithProvenance(provenance))
+		require.NoError(t, err)
+		require.EqualValues(t, "defaults JSON", provenance["Name"])
+	})
+	t.Run("should return error when source fails", func(t *testing.T) {
+		var cfg EnvConfig
+		_, err := Load(&cfg, NewEnvSource(WithDotEnv(filepath.Join(t.TempDir(), "missing.env"))))
+		require.ErrorContains(t, err, "read dotenv file")
+
+		_, err = Load(&cfg, MapSource{"Server.Port": "http"})
+		require.ErrorContains(t, err, "cannot set default value for Server.Port, parse http to int failed")
+	})
+}
+
+func TestNewJSONFileSource(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(name, []byte(`{"Server.Port": 7000}`), 0600))
+
+	src, err := NewJSONFileSource(name)
+	require.NoError(t, err)
+	var cfg EnvConfig
+	provenance, err := Load(&cfg, src)
+	require.NoError(t, err)
+	require.EqualValues(t, 7000, cfg.Server.Port)
+	require.EqualValues(t, "file "+name, provenance["Server.Port"])
+
+	_, err = NewJSONFileSource(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
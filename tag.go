@@ -0,0 +1,221 @@
+package go_default
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tag is a parsed default tag
+//
+// The grammar of a tag is:
+//
+//	tag    = value { "," option }
+//	value  = quoted | raw
+//	option = name "=" ( quoted | raw )
+//	name   = letter { letter | digit | "_" | "-" }
+//	quoted = "'" { char | escape } "'"
+//
+// The values are read as follows:
+//   - a raw value is taken verbatim up to the first "," followed by an option name and "=", so values like
+//     "Fri, 10 Jan 2025" or `json:{"a":1,"b":2}` need no quoting
+//   - a quoted value supports the Go escape sequences, like "\n", "\u00e9" and "\'", and is always a literal,
+//     so "'dive'" and "'-'" are the strings "dive" and "-" rather than the dive directive and the skip marker
+//
+// For example, "'a,b=c',sep=';'" or "2025/01/10,layout=2006/01/02".
+type Tag struct {
+	Value   string            // the default value, unquoted and unescaped
+	Quoted  bool              // whether the value was quoted
+	Options map[string]string // the options after the value, like "layout" or "sep"
+}
+
+// TagSetter set the default value for a field from its parsed tag, so it can read the tag options
+//
+//   - path is the full path of the field, like "foo.bar.baz"
+//   - fieldValue is the reflect.Value of the field
+//   - tag is the parsed default tag
+type TagSetter func(path string, fieldValue reflect.Value, tag Tag) (set bool, err error)
+
+// ParseTag parse a default tag, see Tag for the grammar
+func ParseTag(s string) (Tag, error) {
+	var tag Tag
+	var err error
+	tag.Value, tag.Quoted, s, err = parseTagValue(s)
+	if err != nil {
+		return Tag{}, err
+	}
+	for s != "" {
+		s = s[1:] // skip ","
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 || !isOptionName(s[:eq]) {
+			return Tag{}, fmt.Errorf("invalid tag option %q", s)
+		}
+		name := s[:eq]
+		var value string
+		value, _, s, err = parseTagValue(s[eq+1:])
+		if err != nil {
+			return Tag{}, fmt.Errorf("invalid tag option %s: %w", name, err)
+		}
+		if tag.Options == nil {
+			tag.Options = map[string]string{}
+		}
+		tag.Options[name] = value
+	}
+	return tag, nil
+}
+
+// Option return the value of a tag option
+func (t Tag) Option(name string) (string, bool) {
+	value, ok := t.Options[name]
+	return value, ok
+}
+
+// IsDive report whether the tag is the dive directive
+func (t Tag) IsDive() bool {
+	return !t.Quoted && t.Value == "dive"
+}
+
+// IsSkip report whether the tag is the skip marker "-", the field and its whole subtree are never defaulted
+func (t Tag) IsSkip() bool {
+	return !t.Quoted && t.Value == "-"
+}
+
+// checkOptions check that every option of a tag is read by a setter, so a typo or a raw value cut at "," is reported
+func (cfg *Config) checkOptions(tag Tag) error {
+	names := make([]string, 0, len(tag.Options))
+	for name := range tag.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		known := false
+		for _, option := range cfg.TagOptions {
+			known = known || option == name
+		}
+		if !known {
+			return fmt.Errorf("unknown tag option %s, quote the value if it contains \",\"", name)
+		}
+	}
+	return nil
+}
+
+// withValue return a copy of the tag with a new value and without the named options
+func (t Tag) withValue(value string, without ...string) Tag {
+	options := make(map[string]string, len(t.Options))
+	for name, v := range t.Options {
+		options[name] = v
+	}
+	for _, name := range without {
+		delete(options, name)
+	}
+	return Tag{Value: value, Options: options}
+}
+
+// parseTagValue parse a quoted or raw value and return the rest, which is empty or starts with ","
+func parseTagValue(s string) (value string, quoted bool, rest string, err error) {
+	if !strings.HasPrefix(s, "'") {
+		end := nextOption(s)
+		return s[:end], false, s[end:], nil
+	}
+
+	var b strings.Builder
+	s = s[1:]
+	for {
+		if s == "" {
+			return "", false, "", fmt.Errorf("unterminated quoted value")
+		}
+		if s[0] == '\'' {
+			s = s[1:]
+			break
+		}
+		r, _, tail, err := strconv.UnquoteChar(s, '\'')
+		if err != nil {
+			return "", false, "", fmt.Errorf("invalid escape in quoted value: %w", err)
+		}
+		b.WriteRune(r)
+		s = tail
+	}
+	if s != "" && s[0] != ',' {
+		return "", false, "", fmt.Errorf("unexpected %q after quoted value", s)
+	}
+	return b.String(), true, s, nil
+}
+
+// nextOption return the index of the first "," followed by an option name and "=", or len(s)
+func nextOption(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] != ',' {
+			continue
+		}
+		if eq := strings.IndexByte(s[i+1:], '='); eq >= 0 && isOptionName(s[i+1:i+1+eq]) {
+			return i
+		}
+	}
+	return len(s)
+}
+
+func isOptionName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case i > 0 && (c >= '0' && c <= '9' || c == '_' || c == '-'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+var timeLayouts = map[string]string{
+	"Layout":      time.Layout,
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"StampMilli":  time.StampMilli,
+	"StampMicro":  time.StampMicro,
+	"StampNano":   time.StampNano,
+}
+
+// TimeLayoutSetter set the default value for time.Time with the "layout" option
+//
+// The layout can be a Go layout or the name of a layout constant in the time package, like
+// "2025/01/10,layout=2006/01/02" or "Fri, 10 Jan 2025 17:20:00 UTC,layout=RFC1123".
+// Quoted values without a layout use time.RFC3339, so they are never split by TimeSetter.
+func TimeLayoutSetter(path string, fieldValue reflect.Value, tag Tag) (set bool, err error) {
+	if fieldValue.Type() != reflect.TypeOf(time.Time{}) {
+		return false, nil
+	}
+	layout, ok := tag.Option("layout")
+	if !ok && !tag.Quoted {
+		return false, nil
+	}
+	if !fieldValue.Interface().(time.Time).IsZero() {
+		return true, nil // already set
+	}
+	if layout == "" {
+		layout = time.RFC3339
+	} else if named, ok := timeLayouts[layout]; ok {
+		layout = named
+	}
+	t, err := time.Parse(layout, tag.Value)
+	if err != nil {
+		return false, fmt.Errorf("cannot set default value for %s, parse %s to %s failed", path, tag.Value, fieldValue.Type().String())
+	}
+	fieldValue.Set(reflect.ValueOf(t))
+	return true, nil
+}
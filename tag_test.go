@@ -0,0 +1,183 @@
+package go_default
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want Tag
+	}{
+		{tag: "hello", want: Tag{Value: "hello"}},
+		{tag: "", want: Tag{}},
+		{tag: "a,b", want: Tag{Value: "a,b"}},
+		{tag: "Fri, 10 Jan 2025 17:20:00 UTC;Mon, 02 Jan 2006 15:04:05 MST", want: Tag{Value: "Fri, 10 Jan 2025 17:20:00 UTC;Mon, 02 Jan 2006 15:04:05 MST"}},
+		{tag: `json:{"a":1,"b":2}`, want: Tag{Value: `json:{"a":1,"b":2}`}},
+		{tag: "ff,base=16", want: Tag{Value: "ff", Options: map[string]string{"base": "16"}}},
+		{tag: "a;b,sep=;,x-y=1", want: Tag{Value: "a;b", Options: map[string]string{"sep": ";", "x-y": "1"}}},
+		{tag: "10 Jan 25,layout=02 Jan 06, 15:04", want: Tag{Value: "10 Jan 25", Options: map[string]string{"layout": "02 Jan 06, 15:04"}}},
+		{tag: "'dive'", want: Tag{Value: "dive", Quoted: true}},
+		{tag: `'a,b=c',sep=','`, want: Tag{Value: "a,b=c", Quoted: true, Options: map[string]string{"sep": ","}}},
+		{tag: `'line1\nline2 é \'quoted\' \\'`, want: Tag{Value: "line1\nline2 é 'quoted' \\", Quoted: true}},
+		{tag: "''", want: Tag{Value: "", Quoted: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			tag, err := ParseTag(tt.tag)
+			require.NoError(t, err)
+			require.EqualValues(t, tt.want, tag)
+		})
+	}
+
+	t.Run("should return error when tag is malformed", func(t *testing.T) {
+		_, err := ParseTag("'unterminated")
+		require.ErrorContains(t, err, "unterminated quoted value")
+		_, err = ParseTag("'value'trailing")
+		require.ErrorContains(t, err, `unexpected "trailing" after quoted value`)
+		_, err = ParseTag(`'\q'`)
+		require.ErrorContains(t, err, "invalid escape in quoted value")
+		_, err = ParseTag("'value',sep")
+		require.ErrorContains(t, err, `invalid tag option "sep"`)
+	})
+}
+
+func TestStruct_TagOptions(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		var foo struct {
+			Hex        int             `default:"ff,base=16"`
+			Binary     uint8           `default:"0101,base=2"`
+			Layout     time.Time       `default:"2025/01/10,layout=2006/01/02"`
+			NamedLay   time.Time       `default:"Fri, 10 Jan 2025 17:20:00 UTC,layout=RFC1123"`
+			Semicolon  time.Time       `default:"'2025;01;10',layout='2006;01;02'"`
+			Quoted     string          `default:"'a\\tb,c=d'"`
+			QuotedDive string          `default:"'dive'"`
+			Strings    []string        `default:"a,b,c,sep=','"`
+			Ints       []int           `default:"1;2;3,sep=;"`
+			HexInts    []int           `default:"a|b,sep=|,base=16"`
+			Durations  []time.Duration `default:"1s 2m,sep=' '"`
+			Array      [3]int          `default:"1/2,sep=/"`
+			Empty      []string        `default:",sep=/"`
+		}
+		err := Struct(&foo)
+		require.NoError(t, err)
+		require.EqualValues(t, 255, foo.Hex)
+		require.EqualValues(t, 5, foo.Binary)
+		require.EqualValues(t, time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC), foo.Layout)
+		require.EqualValues(t, time.Date(2025, 1, 10, 17, 20, 0, 0, time.UTC), foo.NamedLay)
+		require.EqualValues(t, time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC), foo.Semicolon)
+		require.EqualValues(t, "a\tb,c=d", foo.Quoted)
+		require.EqualValues(t, "dive", foo.QuotedDive)
+		require.EqualValues(t, []string{"a", "b", "c"}, foo.Strings)
+		require.EqualValues(t, []int{1, 2, 3}, foo.Ints)
+		require.EqualValues(t, []int{10, 11}, foo.HexInts)
+		require.EqualValues(t, []time.Duration{time.Second, 2 * time.Minute}, foo.Durations)
+		require.EqualValues(t, [3]int{1, 2, 0}, foo.Array)
+		require.Empty(t, foo.Empty)
+	})
+	t.Run("not set", func(t *testing.T) {
+		var foo struct {
+			Strings []string `default:"a,b,sep=','"`
+		}
+		foo.Strings = []string{"z"}
+		err := Struct(&foo)
+		require.NoError(t, err)
+		require.EqualValues(t, []string{"z"}, foo.Strings)
+	})
+	t.Run("should return error when tag is malformed", func(t *testing.T) {
+		var foo struct {
+			String string `default:"'unterminated"`
+		}
+		err := Struct(&foo)
+		require.ErrorContains(t, err, "cannot set default value for String, parse tag 'unterminated failed: unterminated quoted value")
+	})
+	t.Run("should return error when element fails to parse", func(t *testing.T) {
+		var foo struct {
+			Ints []int `default:"1,x,sep=','"`
+		}
+		err := Struct(&foo)
+		require.ErrorContains(t, err, "cannot set default value for Ints[1], parse x to int failed")
+	})
+	t.Run("should return error when option is unknown", func(t *testing.T) {
+		var foo struct {
+			Pair string `default:"a=1,b=2"`
+		}
+		err := Struct(&foo)
+		require.EqualError(t, err, `cannot set default value for Pair, unknown tag option b, quote the value if it contains ","`)
+
+		var typo struct {
+			Hex int `default:"10,bsae=16"`
+		}
+		err = Struct(&typo)
+		require.EqualError(t, err, `cannot set default value for Hex, unknown tag option bsae, quote the value if it contains ","`)
+	})
+	t.Run("custom option", func(t *testing.T) {
+		var foo struct {
+			Pair string `default:"'a=1,b=2'"`
+			Port int    `default:"80,unit=tcp"`
+		}
+		err := Struct(&foo, WithTagOptions(append(DefaultTagOptions(), "unit")...))
+		require.NoError(t, err)
+		require.EqualValues(t, "a=1,b=2", foo.Pair)
+		require.EqualValues(t, 80, foo.Port)
+	})
+	t.Run("should return error when quoted value is set to a struct", func(t *testing.T) {
+		var foo struct {
+			Nested Nested `default:"'dive'"`
+		}
+		err := Struct(&foo)
+		require.ErrorContains(t, err, "cannot set default value for Nested, unknown value dive for go_default.Nested, use dive to fill the nested struct")
+	})
+}
+
+func TestStruct_Skip(t *testing.T) {
+	type Shared struct {
+		String string `default:"world"`
+		Nested Nested `default:"dive"`
+	}
+	var foo struct {
+		Skipped     string  `default:"-"`
+		SkippedTree Shared  `default:"-"`
+		SkippedPtr  *Shared `default:"-"`
+		Literal     string  `default:"'-'"`
+		Shared      Shared  `default:"dive"`
+	}
+	err := Struct(&foo)
+	require.NoError(t, err)
+	require.EqualValues(t, "", foo.Skipped)
+	require.EqualValues(t, Shared{}, foo.SkippedTree)
+	require.Nil(t, foo.SkippedPtr)
+	require.EqualValues(t, "-", foo.Literal)
+	require.EqualValues(t, "world", foo.Shared.String)
+	require.EqualValues(t, "world", foo.Shared.Nested.String)
+}
+
+func TestStruct_UnknownStructValue(t *testing.T) {
+	t.Run("should return error when struct value is unknown", func(t *testing.T) {
+		var foo struct {
+			Nested Nested `default:"diev"`
+		}
+		err := Struct(&foo)
+		require.ErrorContains(t, err, "cannot set default value for Nested, unknown value diev for go_default.Nested, use dive to fill the nested struct")
+	})
+	t.Run("should return error when struct pointer value is unknown", func(t *testing.T) {
+		var foo struct {
+			NestedPtr *Nested `default:"true"`
+		}
+		err := Struct(&foo)
+		require.ErrorContains(t, err, "cannot set default value for NestedPtr, unknown value true for *go_default.Nested")
+		require.Nil(t, foo.NestedPtr)
+	})
+	t.Run("should return error when nested struct value is unknown", func(t *testing.T) {
+		var foo struct {
+			Outer struct {
+				Nested Nested `default:"div"`
+			} `default:"dive"`
+		}
+		err := Struct(&foo)
+		require.ErrorContains(t, err, "cannot set default value for Outer.Nested, unknown value div")
+	})
+}
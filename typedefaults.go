@@ -0,0 +1,93 @@
+package go_default
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var (
+	typeDefaultsMu sync.RWMutex
+	typeDefaults   = map[reflect.Type]map[string]string{}
+)
+
+// RegisterTypeDefaults register the default values of the fields of a struct type, keyed by field name
+//
+// It's useful for types we can't add tags to, like http.Server. The values follow the tag grammar and replace the
+// tags of the fields whenever Struct reaches the type, at any nesting level. A pointer type registers its element type,
+// and registering a type again replaces its values.
+//
+// The fields, the tag options and the values are validated at registration, the options against DefaultTagOptions.
+// Values with "$" or a resolver scheme are only validated when they are resolved, and only the existence of the method
+// of a "method:" value is checked.
+func RegisterTypeDefaults(t reflect.Type, defaults map[string]string) error {
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot register defaults for %v, not a struct type", t)
+	}
+
+	values := make(map[string]string, len(defaults))
+	cfg := newConfig()
+	for name, tagValue := range defaults {
+		field, ok := t.FieldByName(name)
+		if !ok || !field.IsExported() || len(field.Index) > 1 {
+			return fmt.Errorf("cannot register defaults for %s, unknown field %s", t.String(), name)
+		}
+		tag, err := ParseTag(tagValue)
+		if err != nil {
+			return fmt.Errorf("cannot register defaults for %s.%s, parse tag %s failed: %w", t.String(), name, tagValue, err)
+		}
+		if err := cfg.checkOptions(tag); err != nil {
+			return fmt.Errorf("cannot register defaults for %s.%s, %w", t.String(), name, err)
+		}
+		if !tag.Quoted && strings.HasPrefix(tag.Value, MethodPrefix) {
+			// computed when Struct reaches the type, so only the method is checked
+			method := strings.TrimPrefix(tag.Value, MethodPrefix)
+			if _, ok := reflect.PointerTo(t).MethodByName(method); !ok {
+				return fmt.Errorf("cannot register defaults for %s.%s, unknown method %s", t.String(), name, method)
+			}
+		} else if !tag.IsSkip() && !isResolvedLater(tag.Value) {
+			if err := fillSome(name, reflect.New(field.Type).Elem(), tag, cfg); err != nil {
+				return fmt.Errorf("cannot register defaults for %s: %w", t.String(), err)
+			}
+		}
+		values[name] = tagValue
+	}
+
+	typeDefaultsMu.Lock()
+	defer typeDefaultsMu.Unlock()
+	typeDefaults[t] = values
+	return nil
+}
+
+// isResolvedLater report whether a value is expanded or resolved by the options of Struct
+func isResolvedLater(value string) bool {
+	if strings.Contains(value, "$") {
+		return true
+	}
+	scheme, _, ok := strings.Cut(value, ":")
+	if !ok {
+		return false
+	}
+	if _, ok := DefaultResolvers()[scheme]; ok {
+		return true
+	}
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	_, ok = resolvers[scheme]
+	return ok
+}
+
+// tagValueOf return the registered default of a field, or its tag
+func tagValueOf(t reflect.Type, field reflect.StructField, cfg *Config) string {
+	typeDefaultsMu.RLock()
+	value, ok := typeDefaults[t][field.Name]
+	typeDefaultsMu.RUnlock()
+	if ok {
+		return value
+	}
+	return field.Tag.Get(cfg.TagName)
+}
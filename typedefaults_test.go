@@ -0,0 +1,111 @@
+package go_default
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ExternalOptions stands for a type we can't add tags to
+type ExternalOptions struct {
+	Name    string
+	Retries int `default:"1"`
+	Timeout time.Duration
+	Inner   *ExternalInner
+	Tags    []string
+	Skip    string
+}
+
+type ExternalInner struct {
+	Level int
+}
+
+type ExternalWorkers struct {
+	Workers int
+}
+
+func (w *ExternalWorkers) ComputeWorkers() int {
+	return 4
+}
+
+type TypeDefaultsConfig struct {
+	HTTP    http.Server      `default:"dive"`
+	Options *ExternalOptions `default:"dive"`
+	List    []ExternalOptions
+	Nested  struct {
+		Options ExternalOptions `default:"dive"`
+	} `default:"dive"`
+}
+
+func TestRegisterTypeDefaults(t *testing.T) {
+	require.NoError(t, RegisterTypeDefaults(reflect.TypeOf(http.Server{}), map[string]string{
+		"Addr":        ":8080",
+		"ReadTimeout": "5s",
+	}))
+	require.NoError(t, RegisterTypeDefaults(reflect.TypeOf(&ExternalOptions{}), map[string]string{
+		"Name":    "${NAME:-external}",
+		"Retries": "3",
+		"Timeout": "1s",
+		"Inner":   "dive",
+		"Tags":    "a,b,sep=','",
+		"Skip":    "-",
+	}))
+	require.NoError(t, RegisterTypeDefaults(reflect.TypeOf(ExternalInner{}), map[string]string{
+		"Level": "2",
+	}))
+
+	var cfg TypeDefaultsConfig
+	require.NoError(t, Struct(&cfg, WithExpandEnv()))
+	require.EqualValues(t, ":8080", cfg.HTTP.Addr)
+	require.EqualValues(t, 5*time.Second, cfg.HTTP.ReadTimeout)
+	require.Zero(t, cfg.HTTP.WriteTimeout)
+
+	expected := ExternalOptions{
+		Name:    "external",
+		Retries: 3,
+		Timeout: time.Second,
+		Inner:   &ExternalInner{Level: 2},
+		Tags:    []string{"a", "b"},
+	}
+	require.EqualValues(t, expected, *cfg.Options)
+	require.EqualValues(t, expected, cfg.Nested.Options)
+	require.Empty(t, cfg.List)
+
+	t.Run("method", func(t *testing.T) {
+		require.NoError(t, RegisterTypeDefaults(reflect.TypeOf(ExternalWorkers{}), map[string]string{
+			"Workers": "method:ComputeWorkers",
+		}))
+		var workers ExternalWorkers
+		require.NoError(t, Struct(&workers))
+		require.EqualValues(t, 4, workers.Workers)
+	})
+	t.Run("errors", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			t        reflect.Type
+			defaults map[string]string
+			msg      string
+		}{
+			"not struct": {reflect.TypeOf(1), nil, "cannot register defaults for int, not a struct type"},
+			"nil":        {nil, nil, "cannot register defaults for <nil>, not a struct type"},
+			"unknown field": {reflect.TypeOf(ExternalOptions{}), map[string]string{"Nope": "1"},
+				"cannot register defaults for go_default.ExternalOptions, unknown field Nope"},
+			"unexported field": {reflect.TypeOf(http.Server{}), map[string]string{"mu": "1"},
+				"cannot register defaults for http.Server, unknown field mu"},
+			"invalid value": {reflect.TypeOf(ExternalOptions{}), map[string]string{"Retries": "abc"},
+				"cannot register defaults for go_default.ExternalOptions: cannot set default value for Retries, parse abc to int failed"},
+			"invalid tag": {reflect.TypeOf(ExternalOptions{}), map[string]string{"Name": "'abc"},
+				"cannot register defaults for go_default.ExternalOptions.Name, parse tag 'abc failed"},
+			"unknown method": {reflect.TypeOf(ExternalWorkers{}), map[string]string{"Workers": "method:Nope"},
+				"cannot register defaults for go_default.ExternalWorkers.Workers, unknown method Nope"},
+			"unknown option": {reflect.TypeOf(ExternalOptions{}), map[string]string{"Timeout": "5s,unti=ms"},
+				`cannot register defaults for go_default.ExternalOptions.Timeout, unknown tag option unti, quote the value if it contains ","`},
+			"struct without dive": {reflect.TypeOf(ExternalOptions{}), map[string]string{"Inner": "1"},
+				"use dive to fill the nested struct"},
+		} {
+			require.ErrorContains(t, RegisterTypeDefaults(tc.t, tc.defaults), tc.msg, name)
+		}
+	})
+}
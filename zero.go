@@ -0,0 +1,122 @@
+package go_default
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ZeroPolicy report whether a field is unset, so its default value applies
+type ZeroPolicy func(path string, fieldValue reflect.Value) bool
+
+// Zeroer is implemented by a field type to tell whether a value is unset, like time.Time
+type Zeroer interface {
+	IsZero() bool
+}
+
+// WithZeroPolicy decide which fields are unset by policy instead of the built-in zero detection
+//
+// A field the policy reports as unset is reset to its zero value before the default applies.
+func WithZeroPolicy(policy ZeroPolicy) Option {
+	return func(cfg *Config) {
+		cfg.zeroPolicy = policy
+	}
+}
+
+// WithOverwrite set the default values even over the non-zero values
+func WithOverwrite() Option {
+	return func(cfg *Config) {
+		cfg.overwrite = true
+	}
+}
+
+// WithReset reset the fields at the paths and their subtrees to their zero values before filling them, or every field
+// if no path is given. Unknown paths return an error, the paths of the skipped fields, tagged `-`, are known but kept.
+func WithReset(paths ...string) Option {
+	return func(cfg *Config) {
+		cfg.reset = &resetPaths{paths: paths, seen: map[string]bool{}}
+	}
+}
+
+// Reset restore the fields at the paths to their default values, or every field if no path is given
+//
+// It's useful during runtime reconfiguration, e.g. Reset(&cfg, "Server.Timeout", "Log").
+func Reset(input any, paths ...string) error {
+	return Struct(input, WithReset(paths...))
+}
+
+// unset report whether the default value applies to a field, and whether the field must be reset to zero first
+func (cfg *Config) unset(path string, fieldValue reflect.Value) (unset bool, reset bool) {
+	switch {
+	case cfg.overwrite:
+		return true, true
+	case cfg.zeroPolicy != nil:
+		unset = cfg.zeroPolicy(path, fieldValue)
+		return unset, unset
+	}
+	if zeroer, ok := asZeroer(fieldValue); ok {
+		unset = zeroer.IsZero()
+		return unset, unset
+	}
+	return isDefault(fieldValue), false
+}
+
+// asZeroer return the Zeroer of a non-pointer value, by a value or a pointer receiver
+func asZeroer(v reflect.Value) (Zeroer, bool) {
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface || !v.CanInterface() {
+		return nil, false // a nil pointer can't tell, and pointers keep the built-in detection
+	}
+	if v.CanAddr() {
+		v = v.Addr()
+	}
+	zeroer, ok := v.Interface().(Zeroer)
+	return zeroer, ok
+}
+
+// resetPaths select the fields reset by WithReset, and track the matched paths to report the unknown ones
+type resetPaths struct {
+	paths []string
+	seen  map[string]bool
+}
+
+// match report whether the path or one of its ancestors is selected
+func (r *resetPaths) match(path string) bool {
+	if len(r.paths) == 0 {
+		return true
+	}
+	for _, p := range r.paths {
+		if p == path {
+			r.seen[p] = true
+			return true
+		}
+		if isSubpath(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// skip mark the paths at or under a skipped field as known, the field is kept as is
+func (r *resetPaths) skip(path string) {
+	for _, p := range r.paths {
+		if p == path || isSubpath(p, path) {
+			r.seen[p] = true
+		}
+	}
+}
+
+// check return an error listing the paths that no field matched
+func (r *resetPaths) check() error {
+	var unknown []string
+	for _, p := range r.paths {
+		if !r.seen[p] {
+			unknown = append(unknown, p)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("unknown paths in reset: %s", strings.Join(unknown, ", "))
+}
@@ -0,0 +1,122 @@
+package go_default
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Port treats a negative value as unset
+type Port int
+
+func (p *Port) IsZero() bool {
+	return *p <= 0
+}
+
+type ZeroServer struct {
+	Host    string        `default:"localhost"`
+	Port    Port          `default:"8080"`
+	Timeout time.Duration `default:"1s"`
+	URL     *ZeroURL      `default:"dive"`
+	Started time.Time     `default:"2025-01-10T17:20:00Z"`
+	Name    string
+	Tags    []string `default:"a,sep=','"`
+	Skip    string   `default:"-"`
+}
+
+type ZeroURL struct {
+	Scheme string `default:"https"`
+}
+
+type ZeroConfig struct {
+	Server ZeroServer `default:"dive"`
+	Level  int        `default:"3"`
+}
+
+func TestStruct_ZeroPolicy(t *testing.T) {
+	t.Run("IsZero method", func(t *testing.T) {
+		cfg := ZeroConfig{Server: ZeroServer{Port: -1}}
+		require.NoError(t, Struct(&cfg))
+		require.EqualValues(t, 8080, cfg.Server.Port)
+
+		cfg = ZeroConfig{Server: ZeroServer{Port: 9000}}
+		require.NoError(t, Struct(&cfg))
+		require.EqualValues(t, 9000, cfg.Server.Port)
+	})
+	t.Run("policy", func(t *testing.T) {
+		cfg := ZeroConfig{Server: ZeroServer{Host: "TODO", Timeout: time.Minute}, Level: 1}
+		var paths []string
+		require.NoError(t, Struct(&cfg, WithZeroPolicy(func(path string, v reflect.Value) bool {
+			paths = append(paths, path)
+			return v.Kind() == reflect.String && v.String() == "TODO" || v.IsZero()
+		})))
+		require.EqualValues(t, "localhost", cfg.Server.Host)
+		require.EqualValues(t, time.Minute, cfg.Server.Timeout)
+		require.EqualValues(t, 1, cfg.Level)
+		require.Contains(t, paths, "Server.URL.Scheme")
+		require.NotContains(t, paths, "Server.Name")
+	})
+	t.Run("overwrite", func(t *testing.T) {
+		cfg := ZeroConfig{
+			Server: ZeroServer{Host: "a.local", Port: 1, Timeout: time.Minute, Name: "kept", Tags: []string{"x"}, Skip: "kept",
+				Started: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+			Level: 1,
+		}
+		require.NoError(t, Struct(&cfg, WithOverwrite()))
+		require.EqualValues(t, "localhost", cfg.Server.Host)
+		require.EqualValues(t, 8080, cfg.Server.Port)
+		require.EqualValues(t, time.Second, cfg.Server.Timeout)
+		require.EqualValues(t, time.Date(2025, 1, 10, 17, 20, 0, 0, time.UTC), cfg.Server.Started)
+		require.EqualValues(t, []string{"a"}, cfg.Server.Tags)
+		require.EqualValues(t, "kept", cfg.Server.Name)
+		require.EqualValues(t, "kept", cfg.Server.Skip)
+		require.EqualValues(t, 3, cfg.Level)
+	})
+}
+
+func TestReset(t *testing.T) {
+	newConfig := func() ZeroConfig {
+		cfg := ZeroConfig{Server: ZeroServer{Host: "a.local", Timeout: time.Minute, Name: "name", Skip: "kept"}, Level: 1}
+		require.NoError(t, Struct(&cfg))
+		cfg.Server.URL.Scheme = "http"
+		return cfg
+	}
+	t.Run("paths", func(t *testing.T) {
+		cfg := newConfig()
+		require.NoError(t, Reset(&cfg, "Server.Timeout", "Server.URL", "Server.Name"))
+		require.EqualValues(t, "a.local", cfg.Server.Host)
+		require.EqualValues(t, time.Second, cfg.Server.Timeout)
+		require.EqualValues(t, "https", cfg.Server.URL.Scheme)
+		require.Empty(t, cfg.Server.Name)
+		require.EqualValues(t, 1, cfg.Level)
+	})
+	t.Run("subtree", func(t *testing.T) {
+		cfg := newConfig()
+		require.NoError(t, Reset(&cfg, "Server"))
+		require.EqualValues(t, "localhost", cfg.Server.Host)
+		require.EqualValues(t, "https", cfg.Server.URL.Scheme)
+		require.Empty(t, cfg.Server.Name)
+		require.EqualValues(t, 1, cfg.Level)
+	})
+	t.Run("all", func(t *testing.T) {
+		cfg := newConfig()
+		require.NoError(t, Reset(&cfg))
+		require.EqualValues(t, "localhost", cfg.Server.Host)
+		require.EqualValues(t, "https", cfg.Server.URL.Scheme)
+		require.EqualValues(t, "kept", cfg.Server.Skip)
+		require.EqualValues(t, 3, cfg.Level)
+	})
+	t.Run("unknown paths", func(t *testing.T) {
+		cfg := newConfig()
+		err := Reset(&cfg, "Server.Nope", "Levels", "Server.Host")
+		require.EqualError(t, err, "unknown paths in reset: Levels, Server.Nope")
+		require.EqualValues(t, "localhost", cfg.Server.Host)
+	})
+	t.Run("skipped", func(t *testing.T) {
+		cfg := newConfig()
+		require.NoError(t, Reset(&cfg, "Server.Skip"))
+		require.EqualValues(t, "kept", cfg.Server.Skip)
+	})
+}